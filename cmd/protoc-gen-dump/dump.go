@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"gopkg.in/yaml.v3"
+
+	"github.com/amery/protogen/pkg/protogen"
+)
+
+// dump formats
+const (
+	formatJSON      = "json"
+	formatPrototext = "prototext"
+	formatYAML      = "yaml"
+	formatProto     = "proto"
+)
+
+// formatExt returns the extension of the output files
+// of a dump format
+func formatExt(format string) string {
+	switch format {
+	case formatPrototext:
+		return ".txtpb"
+	case formatYAML:
+		return ".yaml"
+	case formatProto:
+		return ".proto"
+	default:
+		return ".json"
+	}
+}
+
+// newTypes builds the extension types defined by the request,
+// used to resolve custom options
+func newTypes(gen *protogen.Plugin) (*dynamicpb.Types, error) {
+	reg, err := gen.Registry()
+	if err != nil {
+		return nil, err
+	}
+	return dynamicpb.NewTypes(reg), nil
+}
+
+// resolve re-parses a message so its custom options are
+// recognised instead of remaining as unknown fields
+func resolve[T proto.Message](m T, types *dynamicpb.Types) (T, error) {
+	b, err := proto.Marshal(m)
+	if err != nil {
+		return m, err
+	}
+
+	out, ok := m.ProtoReflect().New().Interface().(T)
+	if !ok {
+		return m, fmt.Errorf("%T: unexpected message type", m)
+	}
+
+	err = proto.UnmarshalOptions{Resolver: types}.Unmarshal(b, out)
+	return out, err
+}
+
+// encode renders a message in the given dump format
+func encode(m proto.Message, format string) ([]byte, error) {
+	switch format {
+	case formatJSON:
+		return protogen.MarshalFormat(m, protogen.JSONFormat)
+	case formatPrototext:
+		return protogen.MarshalFormat(m, protogen.TextFormat)
+	case formatYAML:
+		b, err := protogen.MarshalFormat(m, protogen.JSONFormat)
+		if err != nil {
+			return nil, err
+		}
+		return jsonToYAML(b)
+	default:
+		return nil, fmt.Errorf("format %q can't encode %s", format,
+			m.ProtoReflect().Descriptor().FullName())
+	}
+}
+
+// jsonToYAML converts JSON into block style YAML preserving
+// the order of the keys
+func jsonToYAML(b []byte) ([]byte, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(b, &node); err != nil {
+		return nil, err
+	}
+	clearYAMLStyle(&node)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func clearYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, n := range node.Content {
+		clearYAMLStyle(n)
+	}
+}
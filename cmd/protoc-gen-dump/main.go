@@ -3,12 +3,14 @@ package main
 
 import (
 	"bytes"
-	"encoding/json"
 	"errors"
 	"io"
 	"log"
 	"os"
 
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
 	"google.golang.org/protobuf/types/pluginpb"
 
 	"github.com/amery/protogen/pkg/protogen"
@@ -74,6 +76,17 @@ func rawOutputName(gen *protogen.Plugin) (string, bool) {
 	return name, name != ""
 }
 
+// dumpParams are the protoc parameters accepted by the dumper
+type dumpParams struct {
+	Format     string `protogen:"format,enum=json|prototext|yaml|proto" help:"encoding of the dump"`
+	Combined   bool   `protogen:"combined" help:"dump the whole request as a single file"`
+	RawRequest string `protogen:"raw_request" help:"where to save the binary request ('none' to disable)"`
+}
+
+var params = &dumpParams{
+	Format: formatJSON,
+}
+
 func generate(gen *protogen.Plugin) error {
 	var err error
 
@@ -89,21 +102,30 @@ func generate(gen *protogen.Plugin) error {
 		}
 	}
 
-	// dump source .proto as .json
+	types, err := newTypes(gen)
+	if err != nil {
+		return err
+	}
+
+	if params.Combined {
+		return generateCombined(gen, types)
+	}
+
+	// dump each source .proto
 	gen.ForEachFile(func(f *protogen.File) {
 		switch {
 		case err != nil:
 			// aborting
 		case f.Generate():
-			err = generateFile(gen, f)
+			err = generateFile(gen, types, f)
 		}
 	})
 
-	return nil
+	return err
 }
 
-func generateFile(gen *protogen.Plugin, f *protogen.File) error {
-	out, err := gen.NewGeneratedFile("%s.json", f.Name())
+func generateFile(gen *protogen.Plugin, types *dynamicpb.Types, f *protogen.File) error {
+	out, err := gen.NewGeneratedFile("%s%s", f.Name(), formatExt(params.Format))
 	if err != nil {
 		return err
 	}
@@ -111,19 +133,88 @@ func generateFile(gen *protogen.Plugin, f *protogen.File) error {
 		_ = out.Discard()
 	}()
 
-	// Encode
-	data, err := json.Marshal(f.Proto())
+	if err := writeFileDump(out, types, f); err != nil {
+		return err
+	}
+
+	return out.Close()
+}
+
+// generateCombined dumps the whole request into a single file
+// named after the first file to generate
+func generateCombined(gen *protogen.Plugin, types *dynamicpb.Types) error {
+	var files []*protogen.File
+	gen.ForEachFile(func(f *protogen.File) {
+		if f.Generate() {
+			files = append(files, f)
+		}
+	})
+
+	if len(files) == 0 {
+		return nil
+	}
+
+	out, err := gen.NewGeneratedFile("%s.req%s", files[0].Base(), formatExt(params.Format))
 	if err != nil {
 		return err
 	}
+	defer func() {
+		_ = out.Discard()
+	}()
 
-	// Write
-	_, err = bytes.NewBuffer(data).WriteTo(out)
-	if err == nil {
-		// success
-		err = out.Close()
+	if params.Format == formatProto {
+		err = writeCombinedSource(out, types, files)
+	} else {
+		err = writeMessageDump(out, types, gen.Request())
+	}
+	if err != nil {
+		return err
+	}
+
+	return out.Close()
+}
+
+func writeFileDump(out *protogen.GeneratedFile, types *dynamicpb.Types, f *protogen.File) error {
+	if params.Format == formatProto {
+		out.SetIndent(protogen.IndentTwoSpaces)
+		return writeProtoSource(out, types, f.Descriptor())
+	}
+
+	return writeMessageDump(out, types, f.Proto())
+}
+
+// writeCombinedSource writes the reconstructed source of
+// multiple files, each preceded by its name
+func writeCombinedSource(out *protogen.GeneratedFile, types *dynamicpb.Types,
+	files []*protogen.File) error {
+	out.SetIndent(protogen.IndentTwoSpaces)
+
+	for i, f := range files {
+		if i > 0 {
+			out.Ln()
+		}
+		out.WriteComment(protogen.SlashComments, " file: "+f.Name())
+		out.Ln()
+
+		if err := writeProtoSource(out, types, f.Descriptor()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeMessageDump[T proto.Message](out *protogen.GeneratedFile, types *dynamicpb.Types, m T) error {
+	m, err := resolve(m, types)
+	if err != nil {
+		return err
 	}
 
+	data, err := encode(m, params.Format)
+	if err != nil {
+		return err
+	}
+
+	_, err = bytes.NewBuffer(data).WriteTo(out)
 	return err
 }
 
@@ -133,6 +224,10 @@ func run(in io.ReadCloser, out io.WriteCloser) error {
 		Stdin:    in,
 		Stdout:   out,
 		Features: pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL,
+		Params:   params,
+
+		MinimumEdition: descriptorpb.Edition_EDITION_2023,
+		MaximumEdition: descriptorpb.Edition_EDITION_2023,
 	}
 
 	return opts.Run(generate)
@@ -141,8 +236,10 @@ func run(in io.ReadCloser, out io.WriteCloser) error {
 func main() {
 	var err error
 	pc := &plugin.Config{
-		Name: cmdName,
-		RunE: run,
+		Name:   cmdName,
+		Short:  "dumps protoc's CodeGeneratorRequest",
+		RunE:   run,
+		Params: params,
 	}
 
 	rootCmd, err := plugin.NewRoot(pc)
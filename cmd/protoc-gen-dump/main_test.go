@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	"github.com/amery/protogen/pkg/protogen"
+	"github.com/amery/protogen/pkg/protogen/protogentest"
+)
+
+func TestGolden(t *testing.T) {
+	setExtraRootFlags(&cobra.Command{})
+
+	tests := []struct {
+		name   string
+		params string
+	}{
+		{"json", "raw_request=none"},
+		{"prototext", "format=prototext,raw_request=none"},
+		{"yaml", "format=yaml,raw_request=none"},
+		{"proto", "format=proto,raw_request=none"},
+		{"combined", "format=proto,combined,raw_request=none"},
+	}
+
+	fsys := os.DirFS(filepath.Join("..", "..", "protos"))
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			*params = dumpParams{Format: formatJSON}
+
+			opts := &protogen.Options{
+				Features: pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL,
+				Params:   params,
+			}
+
+			req := protogentest.Compile(t, fsys, tc.params, "nanogrpc.proto")
+			resp := protogentest.Run(t, opts, req, generate)
+			protogentest.Golden(t, filepath.Join("testdata", tc.name), resp)
+		})
+	}
+}
+
+func TestUnknownParam(t *testing.T) {
+	setExtraRootFlags(&cobra.Command{})
+	*params = dumpParams{Format: formatJSON}
+
+	req := protogentest.Compile(t, os.DirFS(filepath.Join("..", "..", "protos")),
+		"raw_request=none,verbose", "nanogrpc.proto")
+
+	_, err := protogen.NewPlugin(&protogen.Options{Params: params}, req)
+	if !errors.Is(err, protogen.ErrUnknownParam) {
+		t.Errorf("unexpected error %v, expected %v", err, protogen.ErrUnknownParam)
+	}
+}
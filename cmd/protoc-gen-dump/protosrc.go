@@ -0,0 +1,621 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/amery/protogen/pkg/protogen"
+	"github.com/amery/protogen/pkg/protogen/naming"
+)
+
+// maxFieldNumber is the largest field number of a message
+const maxFieldNumber = 536870911
+
+// FileDescriptorProto field numbers
+const (
+	packageTag = 2
+	syntaxTag  = 12
+	editionTag = 14
+)
+
+// protoSource reconstructs the .proto source of a file
+// from its descriptors
+type protoSource struct {
+	out   *protogen.GeneratedFile
+	types *dynamicpb.Types
+	fd    protoreflect.FileDescriptor
+	err   error
+}
+
+func writeProtoSource(out *protogen.GeneratedFile, types *dynamicpb.Types,
+	fd protoreflect.FileDescriptor) error {
+	p := &protoSource{
+		out:   out,
+		types: types,
+		fd:    fd,
+	}
+
+	p.writeFile()
+	return p.err
+}
+
+func (p *protoSource) writeFile() {
+	fd := p.fd
+	locs := fd.SourceLocations()
+
+	if fd.Syntax() == protoreflect.Editions {
+		p.writeLocationComments(locs.ByPath(protoreflect.SourcePath{editionTag}))
+		edition := protodesc.ToFileDescriptorProto(fd).GetEdition()
+		p.out.Ln("edition = ", strconv.Quote(strings.TrimPrefix(edition.String(), "EDITION_")), ";")
+	} else {
+		p.writeLocationComments(locs.ByPath(protoreflect.SourcePath{syntaxTag}))
+		p.out.Ln("syntax = ", strconv.Quote(fd.Syntax().String()), ";")
+	}
+
+	if pkg := fd.Package(); pkg != "" {
+		p.out.Ln()
+		p.writeLocationComments(locs.ByPath(protoreflect.SourcePath{packageTag}))
+		p.out.Ln("package ", pkg, ";")
+	}
+
+	p.writeImports()
+	p.writeOptionStatements(fd)
+
+	for i := 0; i < fd.Enums().Len(); i++ {
+		p.out.Ln()
+		p.writeEnum(fd.Enums().Get(i))
+	}
+
+	for i := 0; i < fd.Messages().Len(); i++ {
+		p.out.Ln()
+		p.writeMessage(fd.Messages().Get(i))
+	}
+
+	p.writeExtensions(fd.Extensions())
+
+	for i := 0; i < fd.Services().Len(); i++ {
+		p.out.Ln()
+		p.writeService(fd.Services().Get(i))
+	}
+}
+
+func (p *protoSource) writeImports() {
+	imports := p.fd.Imports()
+	if imports.Len() == 0 {
+		return
+	}
+
+	p.out.Ln()
+	for i := 0; i < imports.Len(); i++ {
+		imp := imports.Get(i)
+
+		var kind string
+		switch {
+		case imp.IsPublic:
+			kind = "public "
+		case imp.IsWeak:
+			kind = "weak "
+		}
+
+		p.out.Ln("import ", kind, strconv.Quote(imp.Path()), ";")
+	}
+}
+
+func (p *protoSource) writeComments(d protoreflect.Descriptor) {
+	p.writeLocationComments(p.fd.SourceLocations().ByDescriptor(d))
+}
+
+func (p *protoSource) writeLocationComments(loc protoreflect.SourceLocation) {
+	p.out.WriteComments(protogen.SlashComments, protogen.Comments{
+		Leading:         loc.LeadingComments,
+		LeadingDetached: loc.LeadingDetachedComments,
+	})
+}
+
+// trailingComment returns the trailing comment of a descriptor
+// to append to its line, if it fits in one
+func (p *protoSource) trailingComment(d protoreflect.Descriptor) string {
+	loc := p.fd.SourceLocations().ByDescriptor(d)
+
+	s := strings.TrimSuffix(loc.TrailingComments, "\n")
+	if s == "" || strings.Contains(s, "\n") {
+		return ""
+	}
+	return " //" + s
+}
+
+// writeTrailingComment writes the trailing comment of a descriptor
+// after its line, if it doesn't fit in one
+func (p *protoSource) writeTrailingComment(d protoreflect.Descriptor) {
+	loc := p.fd.SourceLocations().ByDescriptor(d)
+
+	if s := strings.TrimSuffix(loc.TrailingComments, "\n"); strings.Contains(s, "\n") {
+		p.out.WriteComment(protogen.SlashComments, s)
+	}
+}
+
+func (p *protoSource) writeMessage(md protoreflect.MessageDescriptor) {
+	p.writeComments(md)
+	p.out.Block("message "+string(md.Name())+" {"+p.trailingComment(md), "}", func() {
+		p.writeTrailingComment(md)
+		p.writeMessageBody(md)
+	})
+}
+
+func (p *protoSource) writeMessageBody(md protoreflect.MessageDescriptor) {
+	p.writeOptionStatements(md)
+	p.writeFields(md.Fields())
+
+	for i := 0; i < md.Enums().Len(); i++ {
+		p.out.Ln()
+		p.writeEnum(md.Enums().Get(i))
+	}
+
+	for i := 0; i < md.Messages().Len(); i++ {
+		if nested := md.Messages().Get(i); !isImplicitMessage(nested) {
+			p.out.Ln()
+			p.writeMessage(nested)
+		}
+	}
+
+	p.writeExtensions(md.Extensions())
+	p.writeExtensionRanges(md)
+	p.writeReserved(fieldRanges(md.ReservedRanges()), md.ReservedNames())
+}
+
+// writeFields writes the fields of a message, oneofs
+// at the position of their first field
+func (p *protoSource) writeFields(fields protoreflect.FieldDescriptors) {
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+
+		od := fd.ContainingOneof()
+		switch {
+		case od == nil || od.IsSynthetic():
+			p.writeField(fd)
+		case od.Fields().Get(0) == fd:
+			p.writeOneof(od)
+		}
+	}
+}
+
+// isImplicitMessage tells if a message is declared by a map field
+// or a group instead of a message statement
+func isImplicitMessage(md protoreflect.MessageDescriptor) bool {
+	if md.IsMapEntry() {
+		return true
+	}
+
+	parent, ok := md.Parent().(protoreflect.MessageDescriptor)
+	if !ok {
+		return false
+	}
+
+	fields := parent.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		if fd := fields.Get(i); isGroup(fd) && fd.Message() == md {
+			return true
+		}
+	}
+	return false
+}
+
+func isGroup(fd protoreflect.FieldDescriptor) bool {
+	return fd.Kind() == protoreflect.GroupKind &&
+		fd.Syntax() == protoreflect.Proto2
+}
+
+func (p *protoSource) writeOneof(od protoreflect.OneofDescriptor) {
+	p.writeComments(od)
+	p.out.Block("oneof "+string(od.Name())+" {"+p.trailingComment(od), "}", func() {
+		p.writeTrailingComment(od)
+		p.writeOptionStatements(od)
+
+		fields := od.Fields()
+		for i := 0; i < fields.Len(); i++ {
+			p.writeField(fields.Get(i))
+		}
+	})
+}
+
+func (p *protoSource) writeField(fd protoreflect.FieldDescriptor) {
+	p.writeComments(fd)
+
+	decl := fmt.Sprintf("%s%s %s = %d", fieldLabel(fd), fieldType(fd), fd.Name(), fd.Number())
+	if isGroup(fd) {
+		// group name and message name match
+		decl = fmt.Sprintf("%sgroup %s = %d", fieldLabel(fd), fd.Message().Name(), fd.Number())
+	}
+
+	decl += p.compactOptions(fd, fieldPseudoOptions(fd)...)
+
+	if isGroup(fd) {
+		p.out.Block(decl+" {"+p.trailingComment(fd), "}", func() {
+			p.writeTrailingComment(fd)
+			p.writeMessageBody(fd.Message())
+		})
+	} else {
+		p.out.Ln(decl, ";", p.trailingComment(fd))
+		p.writeTrailingComment(fd)
+	}
+}
+
+func fieldLabel(fd protoreflect.FieldDescriptor) string {
+	switch {
+	case fd.IsMap():
+		return ""
+	case fd.Cardinality() == protoreflect.Repeated:
+		return "repeated "
+	case fd.Syntax() == protoreflect.Editions:
+		return ""
+	case fd.Cardinality() == protoreflect.Required:
+		return "required "
+	case fd.HasOptionalKeyword():
+		return "optional "
+	case fd.Syntax() == protoreflect.Proto2 && fd.ContainingOneof() == nil:
+		return "optional "
+	default:
+		return ""
+	}
+}
+
+func fieldType(fd protoreflect.FieldDescriptor) string {
+	switch {
+	case fd.IsMap():
+		return fmt.Sprintf("map<%s, %s>", fieldType(fd.MapKey()), fieldType(fd.MapValue()))
+	case fd.Message() != nil:
+		return "." + string(fd.Message().FullName())
+	case fd.Enum() != nil:
+		return "." + string(fd.Enum().FullName())
+	default:
+		return fd.Kind().String()
+	}
+}
+
+// fieldPseudoOptions returns the default and json_name
+// declarations of a field
+func fieldPseudoOptions(fd protoreflect.FieldDescriptor) []string {
+	var out []string
+
+	if fd.HasDefault() {
+		out = append(out, "default = "+defaultValue(fd))
+	}
+
+	if fd.HasJSONName() && fd.JSONName() != naming.JSONName(string(fd.Name())) {
+		out = append(out, "json_name = "+strconv.Quote(fd.JSONName()))
+	}
+
+	return out
+}
+
+func defaultValue(fd protoreflect.FieldDescriptor) string {
+	if ev := fd.DefaultEnumValue(); ev != nil {
+		return string(ev.Name())
+	}
+	return scalarValue(fd, fd.Default())
+}
+
+func (p *protoSource) writeEnum(ed protoreflect.EnumDescriptor) {
+	p.writeComments(ed)
+	p.out.Block("enum "+string(ed.Name())+" {"+p.trailingComment(ed), "}", func() {
+		p.writeTrailingComment(ed)
+		p.writeOptionStatements(ed)
+
+		values := ed.Values()
+		for i := 0; i < values.Len(); i++ {
+			vd := values.Get(i)
+
+			p.writeComments(vd)
+			p.out.Ln(vd.Name(), " = ", vd.Number(), p.compactOptions(vd), ";", p.trailingComment(vd))
+			p.writeTrailingComment(vd)
+		}
+
+		p.writeReserved(enumRanges(ed.ReservedRanges()), ed.ReservedNames())
+	})
+}
+
+func (p *protoSource) writeService(sd protoreflect.ServiceDescriptor) {
+	p.writeComments(sd)
+	p.out.Block("service "+string(sd.Name())+" {"+p.trailingComment(sd), "}", func() {
+		p.writeTrailingComment(sd)
+		p.writeOptionStatements(sd)
+
+		methods := sd.Methods()
+		for i := 0; i < methods.Len(); i++ {
+			p.writeMethod(methods.Get(i))
+		}
+	})
+}
+
+func (p *protoSource) writeMethod(md protoreflect.MethodDescriptor) {
+	var in, out string
+	if md.IsStreamingClient() {
+		in = "stream "
+	}
+	if md.IsStreamingServer() {
+		out = "stream "
+	}
+
+	decl := fmt.Sprintf("rpc %s(%s.%s) returns (%s.%s)", md.Name(),
+		in, md.Input().FullName(), out, md.Output().FullName())
+
+	p.writeComments(md)
+	if opts := p.options(md); len(opts) > 0 {
+		p.out.Block(decl+" {"+p.trailingComment(md), "}", func() {
+			p.writeTrailingComment(md)
+			p.writeOptionStatements(md)
+		})
+	} else {
+		p.out.Ln(decl, ";", p.trailingComment(md))
+		p.writeTrailingComment(md)
+	}
+}
+
+// writeExtensions writes extend blocks grouping the extensions
+// by the message they extend
+func (p *protoSource) writeExtensions(extensions protoreflect.ExtensionDescriptors) {
+	extendees, byExtendee := groupExtensions(extensions)
+
+	for _, name := range extendees {
+		p.out.Ln()
+		p.out.Block("extend ."+string(name)+" {", "}", func() {
+			for _, xd := range byExtendee[name] {
+				p.writeField(xd)
+			}
+		})
+	}
+}
+
+// groupExtensions groups extensions by the message they extend,
+// in order of appearance
+func groupExtensions(extensions protoreflect.ExtensionDescriptors) ([]protoreflect.FullName,
+	map[protoreflect.FullName][]protoreflect.FieldDescriptor) {
+	var extendees []protoreflect.FullName
+	byExtendee := make(map[protoreflect.FullName][]protoreflect.FieldDescriptor)
+
+	for i := 0; i < extensions.Len(); i++ {
+		xd := extensions.Get(i)
+		name := xd.ContainingMessage().FullName()
+		if _, ok := byExtendee[name]; !ok {
+			extendees = append(extendees, name)
+		}
+		byExtendee[name] = append(byExtendee[name], xd)
+	}
+	return extendees, byExtendee
+}
+
+func (p *protoSource) writeExtensionRanges(md protoreflect.MessageDescriptor) {
+	ranges := md.ExtensionRanges()
+	if ranges.Len() == 0 {
+		return
+	}
+
+	p.out.Ln()
+	p.out.Ln("extensions ", strings.Join(fieldRanges(ranges), ", "), ";")
+}
+
+// writeReserved writes the reserved ranges and names of
+// a message or an enum
+func (p *protoSource) writeReserved(ranges []string, names protoreflect.Names) {
+	if len(ranges) > 0 {
+		p.out.Ln()
+		p.out.Ln("reserved ", strings.Join(ranges, ", "), ";")
+	}
+
+	if names.Len() > 0 {
+		p.out.Ln()
+		p.out.Ln("reserved ", strings.Join(p.reservedNames(names), ", "), ";")
+	}
+}
+
+// reservedNames renders reserved names, quoted unless
+// using editions
+func (p *protoSource) reservedNames(names protoreflect.Names) []string {
+	s := make([]string, names.Len())
+	for i := range s {
+		name := string(names.Get(i))
+		if p.fd.Syntax() == protoreflect.Editions {
+			s[i] = name
+		} else {
+			s[i] = strconv.Quote(name)
+		}
+	}
+	return s
+}
+
+// fieldRanges renders the ranges of a message, which exclude the end
+func fieldRanges(ranges protoreflect.FieldRanges) []string {
+	s := make([]string, ranges.Len())
+	for i := range s {
+		r := ranges.Get(i)
+		s[i] = formatRange(r[0], r[1]-1)
+	}
+	return s
+}
+
+// enumRanges renders the ranges of an enum, which include the end
+func enumRanges(ranges protoreflect.EnumRanges) []string {
+	s := make([]string, ranges.Len())
+	for i := range s {
+		r := ranges.Get(i)
+		s[i] = formatRange(r[0], r[1])
+	}
+	return s
+}
+
+func formatRange[T ~int32](start, end T) string {
+	switch {
+	case start == end:
+		return strconv.Itoa(int(start))
+	case end == maxFieldNumber || end == math.MaxInt32:
+		return fmt.Sprintf("%d to max", start)
+	default:
+		return fmt.Sprintf("%d to %d", start, end)
+	}
+}
+
+// writeOptionStatements writes the options of a descriptor
+// as option statements
+func (p *protoSource) writeOptionStatements(d protoreflect.Descriptor) {
+	opts := p.options(d)
+	if len(opts) == 0 {
+		return
+	}
+
+	if _, ok := d.(protoreflect.FileDescriptor); ok {
+		p.out.Ln()
+	}
+
+	for _, s := range opts {
+		p.out.Ln("option ", s, ";")
+	}
+}
+
+// compactOptions renders the options of a field or enum value
+// in the [name = value, ...] form
+func (p *protoSource) compactOptions(d protoreflect.Descriptor, extra ...string) string {
+	opts := append(extra, p.options(d)...)
+	if len(opts) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(opts, ", ") + "]"
+}
+
+// options renders the options of a descriptor as name = value,
+// with custom options resolved
+func (p *protoSource) options(d protoreflect.Descriptor) []string {
+	opts := d.Options()
+	if opts == nil || !opts.ProtoReflect().IsValid() {
+		return nil
+	}
+
+	opts, err := resolve(opts, p.types)
+	if err != nil {
+		p.setError(err)
+		return nil
+	}
+
+	var out []string
+	for _, o := range sortedOptions(opts.ProtoReflect()) {
+		out = append(out, p.formatOption(o)...)
+	}
+	return out
+}
+
+type option struct {
+	fd    protoreflect.FieldDescriptor
+	value protoreflect.Value
+}
+
+// sortedOptions returns the populated options, standard first
+// and then extensions, in field number order
+func sortedOptions(opts protoreflect.Message) []option {
+	var list []option
+	opts.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.FullName() != "google.protobuf.MessageOptions.map_entry" {
+			list = append(list, option{fd, v})
+		}
+		return true
+	})
+
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i].fd, list[j].fd
+		if a.IsExtension() != b.IsExtension() {
+			return b.IsExtension()
+		}
+		return a.Number() < b.Number()
+	})
+	return list
+}
+
+// formatOption renders an option as name = value, once
+// per element if repeated
+func (p *protoSource) formatOption(o option) []string {
+	name := string(o.fd.Name())
+	if o.fd.IsExtension() {
+		name = "(" + string(o.fd.FullName()) + ")"
+	}
+
+	if !o.fd.IsList() {
+		return []string{name + " = " + p.optionValue(o.fd, o.value)}
+	}
+
+	l := o.value.List()
+	out := make([]string, l.Len())
+	for i := range out {
+		out[i] = name + " = " + p.optionValue(o.fd, l.Get(i))
+	}
+	return out
+}
+
+func (p *protoSource) optionValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	switch {
+	case fd.Message() != nil:
+		return p.messageValue(v.Message().Interface())
+	case fd.Enum() != nil:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return strconv.Itoa(int(v.Enum()))
+	default:
+		return scalarValue(fd, v)
+	}
+}
+
+// messageValue renders a message option in the text format,
+// on a single line
+func (p *protoSource) messageValue(m proto.Message) string {
+	b, err := protogen.MarshalFormat(m, protogen.TextFormat)
+	if err != nil {
+		p.setError(err)
+	}
+
+	var fields []string
+	for _, line := range strings.Split(string(b), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			fields = append(fields, line)
+		}
+	}
+
+	if len(fields) == 0 {
+		return "{}"
+	}
+	return "{ " + strings.Join(fields, " ") + " }"
+}
+
+func scalarValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return strconv.Quote(v.String())
+	case protoreflect.BytesKind:
+		return strconv.Quote(string(v.Bytes()))
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		f := v.Float()
+		switch {
+		case math.IsInf(f, 1):
+			return "inf"
+		case math.IsInf(f, -1):
+			return "-inf"
+		case math.IsNaN(f):
+			return "nan"
+		default:
+			return strconv.FormatFloat(f, 'g', -1, 64)
+		}
+	default:
+		return v.String()
+	}
+}
+
+func (p *protoSource) setError(err error) {
+	if p.err == nil {
+		p.err = err
+	}
+}
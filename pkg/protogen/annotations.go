@@ -0,0 +1,82 @@
+package protogen
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Offset returns the current size of the generated content, to be
+// used as begin and end of annotations
+func (f *GeneratedFile) Offset() int {
+	if f.buf == nil {
+		return 0
+	}
+	return f.buf.Len()
+}
+
+// Annotate records that the generated content between the begin
+// and end offsets was produced from the given type
+func (f *GeneratedFile) Annotate(p ProtoTyper, begin, end int) {
+	f.addAnnotation(p, begin, end, nil)
+}
+
+// AnnotateSemantic records that the generated content between the begin
+// and end offsets was produced from the given type, and how it affects it
+func (f *GeneratedFile) AnnotateSemantic(p ProtoTyper, begin, end int,
+	semantic descriptorpb.GeneratedCodeInfo_Annotation_Semantic) {
+	f.addAnnotation(p, begin, end, semantic.Enum())
+}
+
+// PAnnotated adds content in the way of [GeneratedFile.P] and annotates
+// it as produced from the given type
+func (f *GeneratedFile) PAnnotated(p ProtoTyper, values ...any) {
+	f.flushIndent()
+
+	begin := f.Offset()
+	f.P(values...)
+	f.Annotate(p, begin, f.Offset())
+}
+
+func (f *GeneratedFile) addAnnotation(p ProtoTyper, begin, end int,
+	semantic *descriptorpb.GeneratedCodeInfo_Annotation_Semantic) {
+	if IsNil(p) || begin > end {
+		return
+	}
+
+	a := &descriptorpb.GeneratedCodeInfo_Annotation{
+		Path:       p.SourcePath(),
+		SourceFile: proto.String(p.File().Name()),
+		Begin:      proto.Int32(int32(begin)),
+		End:        proto.Int32(int32(end)),
+		Semantic:   semantic,
+	}
+
+	f.annotations = append(f.annotations, a)
+}
+
+// shiftAnnotations moves the annotations at or after the given offset,
+// and extends those spanning over it, when content of the given size
+// is inserted there
+func (f *GeneratedFile) shiftAnnotations(offset, size int) {
+	for _, a := range f.annotations {
+		begin, end := int(a.GetBegin()), int(a.GetEnd())
+
+		switch {
+		case begin >= offset:
+			a.Begin = proto.Int32(int32(begin + size))
+			a.End = proto.Int32(int32(end + size))
+		case end > offset:
+			a.End = proto.Int32(int32(end + size))
+		}
+	}
+}
+
+func (f *GeneratedFile) generatedCodeInfo() *descriptorpb.GeneratedCodeInfo {
+	if len(f.annotations) == 0 {
+		return nil
+	}
+
+	return &descriptorpb.GeneratedCodeInfo{
+		Annotation: f.annotations,
+	}
+}
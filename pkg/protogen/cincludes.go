@@ -0,0 +1,71 @@
+package protogen
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+)
+
+var (
+	_ ImportManager = (*CIncludes)(nil)
+)
+
+// CIncludes is an [ImportManager] for C and C++ source files.
+// Headers given between angle brackets are considered system headers
+// and rendered first, any other is rendered between double quotes.
+type CIncludes struct {
+	system map[string]bool
+	local  map[string]bool
+}
+
+// NewCIncludes creates a new [CIncludes]
+func NewCIncludes() *CIncludes {
+	return &CIncludes{
+		system: make(map[string]bool),
+		local:  make(map[string]bool),
+	}
+}
+
+// Import records a header to be included. C has no qualifiers so
+// the returned string is always empty.
+func (m *CIncludes) Import(header string) string {
+	switch {
+	case strings.HasPrefix(header, "<") && strings.HasSuffix(header, ">"):
+		m.system[header[1:len(header)-1]] = true
+	case strings.HasPrefix(header, `"`) && strings.HasSuffix(header, `"`) && len(header) > 1:
+		m.local[header[1:len(header)-1]] = true
+	case header != "":
+		m.local[header] = true
+	}
+	return ""
+}
+
+// Render returns the list of #include directives, system headers first
+func (m *CIncludes) Render() []byte {
+	if len(m.system) == 0 && len(m.local) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, s := range sortedKeys(m.system) {
+		_, _ = buf.WriteString("#include <" + s + ">\n")
+	}
+	if len(m.system) > 0 && len(m.local) > 0 {
+		_ = buf.WriteByte('\n')
+	}
+	for _, s := range sortedKeys(m.local) {
+		_, _ = buf.WriteString(`#include "` + s + "\"\n")
+	}
+	_ = buf.WriteByte('\n')
+
+	return buf.Bytes()
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,154 @@
+package protogen
+
+import (
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Comments are the comments attached to a declaration
+// on the .proto source
+type Comments struct {
+	// Leading is the comment immediately before the declaration
+	Leading string
+	// Trailing is the comment immediately after the declaration,
+	// or after its opening brace
+	Trailing string
+	// LeadingDetached are the comments before the declaration
+	// separated from it by blank lines
+	LeadingDetached []string
+}
+
+// IsZero tells if there are no comments
+func (c Comments) IsZero() bool {
+	return c.Leading == "" && c.Trailing == "" && len(c.LeadingDetached) == 0
+}
+
+func newComments(loc *descriptorpb.SourceCodeInfo_Location) Comments {
+	if loc == nil {
+		return Comments{}
+	}
+
+	return Comments{
+		Leading:         loc.GetLeadingComments(),
+		Trailing:        loc.GetTrailingComments(),
+		LeadingDetached: loc.GetLeadingDetachedComments(),
+	}
+}
+
+func (f *File) commentsAt(path protoreflect.SourcePath) Comments {
+	return newComments(f.SourceLocation(path))
+}
+
+// Comments returns the comments at the top of the file, attached
+// to the syntax statement or, if missing, the package statement.
+func (f *File) Comments() Comments {
+	for _, tag := range []int32{fileSyntaxTag, filePackageTag} {
+		if loc := f.SourceLocation(protoreflect.SourcePath{tag}); loc != nil {
+			return newComments(loc)
+		}
+	}
+	return Comments{}
+}
+
+// Comments returns the comments attached to this message
+func (p *Message) Comments() Comments {
+	return p.File().commentsAt(p.SourcePath())
+}
+
+// Comments returns the comments attached to this enum
+func (p *Enum) Comments() Comments {
+	return p.File().commentsAt(p.SourcePath())
+}
+
+// Comments returns the comments attached to this enum value
+func (p *EnumValue) Comments() Comments {
+	return p.File().commentsAt(p.SourcePath())
+}
+
+// Comments returns the comments attached to this field
+func (p *Field) Comments() Comments {
+	return p.File().commentsAt(p.SourcePath())
+}
+
+// Comments returns the comments attached to this oneof
+func (p *Oneof) Comments() Comments {
+	return p.File().commentsAt(p.SourcePath())
+}
+
+// Comments returns the comments attached to this service
+func (p *Service) Comments() Comments {
+	return p.File().commentsAt(p.SourcePath())
+}
+
+// Comments returns the comments attached to this method
+func (p *Method) Comments() Comments {
+	return p.File().commentsAt(p.SourcePath())
+}
+
+// CommentStyle indicates how comments are rendered on generated code
+type CommentStyle int
+
+const (
+	// SlashComments renders comments as // lines
+	SlashComments CommentStyle = iota
+	// HashComments renders comments as # lines
+	HashComments
+	// BlockComments renders comments as /** */ blocks
+	BlockComments
+)
+
+// Format renders a comment text in the given style, one
+// line per line of text, ending with a new line.
+// Empty text renders nothing.
+func (style CommentStyle) Format(text string) string {
+	if text == "" {
+		return ""
+	}
+
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+
+	switch style {
+	case BlockComments:
+		return "/**\n" + formatCommentLines(" *", lines) + " */\n"
+	case HashComments:
+		return formatCommentLines("#", lines)
+	default:
+		return formatCommentLines("//", lines)
+	}
+}
+
+func formatCommentLines(prefix string, lines []string) string {
+	var buf strings.Builder
+	for _, s := range lines {
+		_, _ = buf.WriteString(strings.TrimRight(prefix+s, " \t") + "\n")
+	}
+	return buf.String()
+}
+
+// WriteComment writes a comment text in the given style
+func (f *GeneratedFile) WriteComment(style CommentStyle, text string) {
+	f.P(style.Format(text))
+}
+
+// FormatComments renders the detached comments, each followed by
+// an empty line, and then the leading comment in the given style.
+// Trailing comments are left to the caller.
+func (style CommentStyle) FormatComments(c Comments) string {
+	var buf strings.Builder
+	for _, s := range c.LeadingDetached {
+		if s != "" {
+			_, _ = buf.WriteString(style.Format(s) + "\n")
+		}
+	}
+
+	_, _ = buf.WriteString(style.Format(c.Leading))
+	return buf.String()
+}
+
+// WriteComments writes the detached comments and the leading
+// comment in the way of [CommentStyle.FormatComments]
+func (f *GeneratedFile) WriteComments(style CommentStyle, c Comments) {
+	f.P(style.FormatComments(c))
+}
@@ -0,0 +1,101 @@
+package protogen
+
+import (
+	"context"
+	"io"
+	"io/fs"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/bufbuild/protocompile/linker"
+	"github.com/bufbuild/protocompile/reporter"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// CompileRequest parses .proto sources from a [fs.FS] and assembles
+// a [pluginpb.CodeGeneratorRequest] to generate the given files, including
+// all their dependencies and SourceCodeInfo, as protoc would do.
+// The well-known google/protobuf files are used when not found on fsys.
+// params is the comma-separated list of generator parameters.
+func CompileRequest(fsys fs.FS, files []string, params string) (*pluginpb.CodeGeneratorRequest, error) {
+	var errs ErrAggregation
+
+	c := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: func(name string) (io.ReadCloser, error) {
+				return fsys.Open(name)
+			},
+		}),
+		SourceInfoMode: protocompile.SourceInfoStandard,
+		Reporter: reporter.NewReporter(func(err reporter.ErrorWithPos) error {
+			errs.Append(newCompileError(err))
+			return nil
+		}, nil),
+	}
+
+	result, err := c.Compile(context.Background(), files...)
+	switch {
+	case errs.AsError() != nil:
+		return nil, errs.AsError()
+	case err != nil:
+		return nil, Wrap(err, "CompileRequest")
+	}
+
+	return newCompiledRequest(result, files, params), nil
+}
+
+func newCompileError(err reporter.ErrorWithPos) error {
+	pos := err.GetPosition()
+
+	return &PluginError{
+		Path:   pos.Filename,
+		Line:   pos.Line,
+		Column: pos.Col,
+		Err:    err.Unwrap(),
+	}
+}
+
+func newCompiledRequest(result linker.Files, files []string, params string) *pluginpb.CodeGeneratorRequest {
+	req := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: files,
+		Parameter:      PointerOrNil(params),
+	}
+
+	// dependencies first
+	seen := make(map[string]bool)
+	var add func(fd protoreflect.FileDescriptor)
+	add = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			add(imports.Get(i).FileDescriptor)
+		}
+
+		req.ProtoFile = append(req.ProtoFile, compiledFileProto(fd))
+	}
+
+	for _, fd := range result {
+		add(fd)
+	}
+
+	for _, fd := range result {
+		req.SourceFileDescriptors = append(req.SourceFileDescriptors, compiledFileProto(fd))
+	}
+
+	return req
+}
+
+// compiledFileProto returns the [descriptorpb.FileDescriptorProto] of
+// a compiled file, preserving its SourceCodeInfo
+func compiledFileProto(fd protoreflect.FileDescriptor) *descriptorpb.FileDescriptorProto {
+	if r, ok := fd.(linker.Result); ok {
+		return r.FileDescriptorProto()
+	}
+	return protodesc.ToFileDescriptorProto(fd)
+}
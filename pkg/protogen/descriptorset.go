@@ -0,0 +1,65 @@
+package protogen
+
+import (
+	"io"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// UnmarshalFileDescriptorSet reads the proto encoded representation of a
+// [descriptorpb.FileDescriptorSet], as produced by protoc --descriptor_set_out
+// or buf build, from a [io.Reader]
+func UnmarshalFileDescriptorSet(r io.Reader) (*descriptorpb.FileDescriptorSet, error) {
+	return UnmarshalFileDescriptorSetFormat(r, BinaryFormat)
+}
+
+// UnmarshalFileDescriptorSetFormat reads the representation of a
+// [descriptorpb.FileDescriptorSet] encoded in the given [WireFormat]
+// from a [io.Reader], detecting it if [AutoFormat]
+func UnmarshalFileDescriptorSetFormat(r io.Reader,
+	format WireFormat) (*descriptorpb.FileDescriptorSet, error) {
+	fds := &descriptorpb.FileDescriptorSet{}
+	err := readSelfDescribing(r, fds, format, fds.GetFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return fds, nil
+}
+
+// NewDescriptorSetRequest wraps a [descriptorpb.FileDescriptorSet] into a
+// [pluginpb.CodeGeneratorRequest] to generate the given files. If no files
+// are specified, those not imported by others on the set are used.
+// params is the comma-separated list of generator parameters.
+func NewDescriptorSetRequest(fds *descriptorpb.FileDescriptorSet,
+	files []string, params string) *pluginpb.CodeGeneratorRequest {
+	if len(files) == 0 {
+		files = descriptorSetRoots(fds)
+	}
+
+	return &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: files,
+		Parameter:      PointerOrNil(params),
+		ProtoFile:      fds.File,
+	}
+}
+
+// descriptorSetRoots returns the names of the files of the set
+// not imported by any other
+func descriptorSetRoots(fds *descriptorpb.FileDescriptorSet) []string {
+	imported := make(map[string]bool)
+	for _, fd := range fds.File {
+		for _, dep := range fd.Dependency {
+			imported[dep] = true
+		}
+	}
+
+	var out []string
+	for _, fd := range fds.File {
+		if name := fd.GetName(); !imported[name] {
+			out = append(out, name)
+		}
+	}
+	return out
+}
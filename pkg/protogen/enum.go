@@ -12,12 +12,14 @@ var (
 
 // Enum represents an enumeration type
 type Enum struct {
-	file *File
-	msg  *Message
-	dp   *descriptorpb.EnumDescriptorProto
+	file  *File
+	msg   *Message
+	dp    *descriptorpb.EnumDescriptorProto
+	index int
 
 	values   []*EnumValue
 	min, max int32
+	features *descriptorpb.FeatureSet
 }
 
 // Request returns the [pluginpb.CodeGeneratorRequest] received by
@@ -93,8 +95,8 @@ func (p *Enum) init() {
 
 	p.values = make([]*EnumValue, 0, len(p.dp.Value))
 
-	for _, dp := range p.dp.Value {
-		next = p.newValue(dp, next)
+	for i, dp := range p.dp.Value {
+		next = p.newValue(dp, i, next)
 	}
 
 	// sort values
@@ -105,8 +107,9 @@ func (p *Enum) init() {
 
 // EnumValue represents a possible value of a [Enum]
 type EnumValue struct {
-	enum *Enum
-	dp   *descriptorpb.EnumValueDescriptorProto
+	enum  *Enum
+	dp    *descriptorpb.EnumValueDescriptorProto
+	index int
 
 	number int32
 }
@@ -160,12 +163,13 @@ func (p *EnumValue) Number() int {
 	return int(p.number)
 }
 
-func (p *Enum) newValue(dp *descriptorpb.EnumValueDescriptorProto, next int32) int32 {
+func (p *Enum) newValue(dp *descriptorpb.EnumValueDescriptorProto, index int, next int32) int32 {
 	cur := optional(dp.Number, next)
 
 	v := &EnumValue{
 		enum:   p,
 		dp:     dp,
+		index:  index,
 		number: cur,
 	}
 
@@ -226,7 +230,7 @@ func (f *File) EnumByName(name string) *Enum {
 
 func loadEnums(ref Enum, enums []*descriptorpb.EnumDescriptorProto) []*Enum {
 	out := make([]*Enum, 0, len(enums))
-	for _, dp := range enums {
+	for i, dp := range enums {
 		if dp == nil {
 			// TODO: log error
 			continue
@@ -234,6 +238,7 @@ func loadEnums(ref Enum, enums []*descriptorpb.EnumDescriptorProto) []*Enum {
 
 		q := ref
 		q.dp = dp
+		q.index = i
 
 		q.init()
 		out = append(out, &q)
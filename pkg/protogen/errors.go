@@ -13,6 +13,11 @@ var (
 	ErrInvalidName = errors.New("invalid name")
 	// ErrInvalidUTF8Content tells the plugin generated unacceptable content
 	ErrInvalidUTF8Content = errors.New("invalid UTF-8 content generated")
+	// ErrUnsupportedEdition tells the plugin can't handle the edition
+	// of a proto file
+	ErrUnsupportedEdition = errors.New("unsupported edition")
+	// ErrUnresolvedType tells a type reference couldn't be resolved
+	ErrUnresolvedType = errors.New("unresolved type reference")
 
 	// ErrUnknownParam tells the plug-in parameter isn't recognized
 	ErrUnknownParam = errors.New("unknown protoc option")
@@ -62,15 +67,43 @@ func Wrap(err error, hint string, args ...any) error {
 	}
 }
 
-// PluginError is a wrapped error referencing a .proto file
+// PluginError is a wrapped error referencing a .proto file,
+// and optionally a position within it
 type PluginError struct {
-	Path string
-	Hint string
-	Err  error
+	Path   string
+	Line   int
+	Column int
+	Hint   string
+	Err    error
+}
+
+// NewPluginError wraps an error with a formatted hint message
+// referencing a [Location]
+func NewPluginError(loc Location, err error, hint string, args ...any) *PluginError {
+	if len(args) > 0 {
+		hint = fmt.Sprintf(hint, args...)
+	}
+
+	return &PluginError{
+		Path:   loc.File,
+		Line:   loc.StartLine,
+		Column: loc.StartColumn,
+		Hint:   hint,
+		Err:    err,
+	}
+}
+
+// Location returns the [Location] referenced by the error
+func (e PluginError) Location() Location {
+	return Location{
+		File:        e.Path,
+		StartLine:   e.Line,
+		StartColumn: e.Column,
+	}
 }
 
 func (e PluginError) Error() string {
-	s0 := e.Path
+	s0 := e.Location().String()
 
 	s1 := e.Hint
 	if s1 == "" && e.Err != nil {
@@ -0,0 +1,147 @@
+package protogen
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// optionsOf returns the options message of a descriptor wrapper,
+// or nil if it has none
+func optionsOf(p any) proto.Message {
+	var m proto.Message
+
+	switch v := p.(type) {
+	case *File:
+		m = v.dp.GetOptions()
+	case *Message:
+		m = v.dp.GetOptions()
+	case *Field:
+		m = v.dp.GetOptions()
+	case *Oneof:
+		m = v.dp.GetOptions()
+	case *Enum:
+		m = v.dp.GetOptions()
+	case *EnumValue:
+		m = v.dp.GetOptions()
+	case *Service:
+		m = v.dp.GetOptions()
+	case *Method:
+		m = v.dp.GetOptions()
+	}
+
+	if IsNil(m) {
+		return nil
+	}
+	return m
+}
+
+// HasOption tells if a descriptor wrapper ([File], [Message], [Field],
+// [Oneof], [Enum], [EnumValue], [Service] or [Method]) has the given
+// custom option set.
+func HasOption(p any, xt protoreflect.ExtensionType) bool {
+	_, ok := lookupOption(p, xt)
+	return ok
+}
+
+// GetOption extracts a custom option from a descriptor wrapper ([File],
+// [Message], [Field], [Oneof], [Enum], [EnumValue], [Service] or [Method]).
+// Options that were received as unknown fields, because the extension
+// wasn't registered when the request was decoded, are parsed on demand.
+// If the option isn't set or T doesn't match the extension's type, the zero
+// value and false are returned.
+func GetOption[T any](p any, xt protoreflect.ExtensionType) (T, bool) {
+	var zero T
+
+	v, ok := lookupOption(p, xt)
+	if !ok {
+		return zero, false
+	}
+
+	out, ok := v.(T)
+	return out, ok
+}
+
+func lookupOption(p any, xt protoreflect.ExtensionType) (any, bool) {
+	opts := optionsOf(p)
+	switch {
+	case opts == nil || xt == nil:
+		return nil, false
+	case xt.TypeDescriptor().ContainingMessage().FullName() !=
+		opts.ProtoReflect().Descriptor().FullName():
+		// wrong kind of options
+		return nil, false
+	}
+
+	v, ok := findExtension(opts, xt)
+	if ok || len(opts.ProtoReflect().GetUnknown()) == 0 {
+		return v, ok
+	}
+
+	// try again with the unknown fields decoded using the given extension
+	opts, err := reparseOptions(opts, xt)
+	if err != nil {
+		return nil, false
+	}
+	return findExtension(opts, xt)
+}
+
+// findExtension looks for a populated extension by name, as the
+// descriptors of the options message may not be the same instance
+// [proto.HasExtension] expects.
+func findExtension(opts proto.Message, xt protoreflect.ExtensionType) (any, bool) {
+	var out any
+	var found bool
+
+	name := xt.TypeDescriptor().FullName()
+	opts.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.IsExtension() && fd.FullName() == name {
+			out, found = xt.InterfaceOf(v), true
+			return false
+		}
+		return true
+	})
+
+	return out, found
+}
+
+func reparseOptions(opts proto.Message, xt protoreflect.ExtensionType) (proto.Message, error) {
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	out := opts.ProtoReflect().New().Interface()
+	err = proto.UnmarshalOptions{
+		Resolver: extensionResolver{xt},
+	}.Unmarshal(b, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+var (
+	_ protoregistry.ExtensionTypeResolver = extensionResolver{}
+)
+
+// extensionResolver resolves a single extension type, falling back
+// to the global registry for anything else
+type extensionResolver struct {
+	xt protoreflect.ExtensionType
+}
+
+func (r extensionResolver) FindExtensionByName(field protoreflect.FullName) (protoreflect.ExtensionType, error) {
+	if xd := r.xt.TypeDescriptor(); xd.FullName() == field {
+		return r.xt, nil
+	}
+	return protoregistry.GlobalTypes.FindExtensionByName(field)
+}
+
+func (r extensionResolver) FindExtensionByNumber(message protoreflect.FullName,
+	field protoreflect.FieldNumber) (protoreflect.ExtensionType, error) {
+	if xd := r.xt.TypeDescriptor(); xd.ContainingMessage().FullName() == message && xd.Number() == field {
+		return r.xt, nil
+	}
+	return protoregistry.GlobalTypes.FindExtensionByNumber(message, field)
+}
@@ -0,0 +1,153 @@
+package protogen
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+const (
+	testMaxSizeNumber = 50001
+	testLabelNumber   = 50002
+)
+
+// newTestExtensions builds the test.max_size field option and the
+// test.label message option, not known to the global registry
+func newTestExtensions(t *testing.T) (maxSize, label protoreflect.ExtensionType) {
+	t.Helper()
+
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("test/options.proto"),
+		Package:    proto.String("test"),
+		Dependency: []string{"google/protobuf/descriptor.proto"},
+		Extension: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     proto.String("max_size"),
+				Number:   proto.Int32(testMaxSizeNumber),
+				Label:    labelOptional.Enum(),
+				Type:     typeInt32.Enum(),
+				Extendee: proto.String(".google.protobuf.FieldOptions"),
+			},
+			{
+				Name:     proto.String("label"),
+				Number:   proto.Int32(testLabelNumber),
+				Label:    labelOptional.Enum(),
+				Type:     typeString.Enum(),
+				Extendee: proto.String(".google.protobuf.MessageOptions"),
+			},
+		},
+	}
+
+	fd, err := protodesc.NewFile(fdp, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	xds := fd.Extensions()
+	return dynamicpb.NewExtensionType(xds.Get(0)), dynamicpb.NewExtensionType(xds.Get(1))
+}
+
+// newOptionsTestPlugin loads a message with fields carrying the
+// max_size option as unknown fields, as received from protoc,
+// and as a populated extension
+func newOptionsTestPlugin(t *testing.T, maxSize, label protoreflect.ExtensionType) *Plugin {
+	t.Helper()
+
+	unknown := &descriptorpb.FieldOptions{}
+	b := protowire.AppendTag(nil, testMaxSizeNumber, protowire.VarintType)
+	b = protowire.AppendVarint(b, 16)
+	unknown.ProtoReflect().SetUnknown(b)
+
+	populated := &descriptorpb.FieldOptions{}
+	proto.SetExtension(populated, maxSize, int32(32))
+
+	msgOptions := &descriptorpb.MessageOptions{}
+	proto.SetExtension(msgOptions, label, "hello")
+
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("test.proto"),
+		Package: proto.String("test"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name:    proto.String("M"),
+				Options: msgOptions,
+				Field: []*descriptorpb.FieldDescriptorProto{
+					newTestField("unknown", 1, labelOptional, typeString),
+					newTestField("populated", 2, labelOptional, typeString),
+					newTestField("none", 3, labelOptional, typeString),
+				},
+			},
+		},
+	}
+
+	fields := fdp.MessageType[0].Field
+	fields[0].Options = unknown
+	fields[1].Options = populated
+
+	gen, err := NewPlugin(nil, &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{"test.proto"},
+		ProtoFile:      []*descriptorpb.FileDescriptorProto{fdp},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gen
+}
+
+func TestGetOption(t *testing.T) {
+	maxSize, label := newTestExtensions(t)
+	gen := newOptionsTestPlugin(t, maxSize, label)
+	msg := gen.FileByName("test.proto").MessageByName("M")
+
+	tests := []struct {
+		name string
+		p    any
+		xt   protoreflect.ExtensionType
+		want any
+		ok   bool
+	}{
+		{"unknown field", msg.FieldByName("unknown"), maxSize, int32(16), true},
+		{"populated", msg.FieldByName("populated"), maxSize, int32(32), true},
+		{"not set", msg.FieldByName("none"), maxSize, nil, false},
+		{"message option", msg, label, "hello", true},
+		{"wrong options", msg, maxSize, nil, false},
+		{"no options", gen.FileByName("test.proto"), label, nil, false},
+		{"no extension", msg, nil, nil, false},
+		{"not a descriptor", "M", label, nil, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := GetOption[any](tc.p, tc.xt)
+			switch {
+			case ok != tc.ok:
+				t.Errorf("GetOption() ok = %v, expected %v", ok, tc.ok)
+			case got != tc.want:
+				t.Errorf("GetOption() = %#v, expected %#v", got, tc.want)
+			case HasOption(tc.p, tc.xt) != tc.ok:
+				t.Errorf("HasOption() = %v, expected %v", !tc.ok, tc.ok)
+			}
+		})
+	}
+}
+
+func TestGetOptionType(t *testing.T) {
+	maxSize, label := newTestExtensions(t)
+	gen := newOptionsTestPlugin(t, maxSize, label)
+	p := gen.FileByName("test.proto").MessageByName("M").FieldByName("unknown")
+
+	if v, ok := GetOption[int32](p, maxSize); !ok || v != 16 {
+		t.Errorf("GetOption[int32]() = %v, %v, expected 16, true", v, ok)
+	}
+
+	if v, ok := GetOption[string](p, maxSize); ok || v != "" {
+		t.Errorf("GetOption[string]() = %q, %v, expected \"\", false", v, ok)
+	}
+}
@@ -0,0 +1,189 @@
+package protogen
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Edition returns the edition of the proto file, mapping proto2 and
+// proto3 syntax into their legacy editions
+func (f *File) Edition() descriptorpb.Edition {
+	switch f.Syntax() {
+	case "editions":
+		return f.dp.GetEdition()
+	case "proto3":
+		return descriptorpb.Edition_EDITION_PROTO3
+	default:
+		return descriptorpb.Edition_EDITION_PROTO2
+	}
+}
+
+func (gen *Plugin) checkEditions() error {
+	for _, f := range gen.files {
+		if f.Generate() && !gen.options.SupportsEdition(f.Edition()) {
+			return NewPluginError(f.Location(), ErrUnsupportedEdition,
+				"%s not supported by %s", f.Edition(), gen.options.Name)
+		}
+	}
+	return nil
+}
+
+// EditionDefaults returns the default [descriptorpb.FeatureSet]
+// of a given edition
+func EditionDefaults(edition descriptorpb.Edition) *descriptorpb.FeatureSet {
+	switch {
+	case edition <= descriptorpb.Edition_EDITION_PROTO2:
+		return &descriptorpb.FeatureSet{
+			FieldPresence:         descriptorpb.FeatureSet_EXPLICIT.Enum(),
+			EnumType:              descriptorpb.FeatureSet_CLOSED.Enum(),
+			RepeatedFieldEncoding: descriptorpb.FeatureSet_EXPANDED.Enum(),
+			Utf8Validation:        descriptorpb.FeatureSet_NONE.Enum(),
+			MessageEncoding:       descriptorpb.FeatureSet_LENGTH_PREFIXED.Enum(),
+			JsonFormat:            descriptorpb.FeatureSet_LEGACY_BEST_EFFORT.Enum(),
+		}
+	case edition == descriptorpb.Edition_EDITION_PROTO3:
+		return &descriptorpb.FeatureSet{
+			FieldPresence:         descriptorpb.FeatureSet_IMPLICIT.Enum(),
+			EnumType:              descriptorpb.FeatureSet_OPEN.Enum(),
+			RepeatedFieldEncoding: descriptorpb.FeatureSet_PACKED.Enum(),
+			Utf8Validation:        descriptorpb.FeatureSet_VERIFY.Enum(),
+			MessageEncoding:       descriptorpb.FeatureSet_LENGTH_PREFIXED.Enum(),
+			JsonFormat:            descriptorpb.FeatureSet_ALLOW.Enum(),
+		}
+	default:
+		// 2023 and later
+		return &descriptorpb.FeatureSet{
+			FieldPresence:         descriptorpb.FeatureSet_EXPLICIT.Enum(),
+			EnumType:              descriptorpb.FeatureSet_OPEN.Enum(),
+			RepeatedFieldEncoding: descriptorpb.FeatureSet_PACKED.Enum(),
+			Utf8Validation:        descriptorpb.FeatureSet_VERIFY.Enum(),
+			MessageEncoding:       descriptorpb.FeatureSet_LENGTH_PREFIXED.Enum(),
+			JsonFormat:            descriptorpb.FeatureSet_ALLOW.Enum(),
+		}
+	}
+}
+
+// mergeFeatures returns a copy of the parent's resolved
+// features overridden by the explicitly set ones
+func mergeFeatures(parent, features *descriptorpb.FeatureSet) *descriptorpb.FeatureSet {
+	out := &descriptorpb.FeatureSet{}
+	proto.Merge(out, parent)
+	if features != nil {
+		proto.Merge(out, features)
+	}
+	return out
+}
+
+// Features returns the resolved [descriptorpb.FeatureSet] of the file
+func (f *File) Features() *descriptorpb.FeatureSet {
+	if f.features == nil {
+		f.features = mergeFeatures(EditionDefaults(f.Edition()),
+			f.dp.GetOptions().GetFeatures())
+	}
+	return f.features
+}
+
+// Features returns the resolved [descriptorpb.FeatureSet] of the message,
+// inherited from its parent
+func (p *Message) Features() *descriptorpb.FeatureSet {
+	if p.features == nil {
+		var parent *descriptorpb.FeatureSet
+		if p.msg != nil {
+			parent = p.msg.Features()
+		} else {
+			parent = p.file.Features()
+		}
+
+		p.features = mergeFeatures(parent, p.dp.GetOptions().GetFeatures())
+	}
+	return p.features
+}
+
+// Features returns the resolved [descriptorpb.FeatureSet] of the oneof,
+// inherited from its message
+func (p *Oneof) Features() *descriptorpb.FeatureSet {
+	if p.features == nil {
+		p.features = mergeFeatures(p.msg.Features(), p.dp.GetOptions().GetFeatures())
+	}
+	return p.features
+}
+
+// Features returns the resolved [descriptorpb.FeatureSet] of the field,
+// inherited from its message or oneof. For proto2 and proto3 files
+// the legacy syntax is translated into their equivalent features.
+func (p *Field) Features() *descriptorpb.FeatureSet {
+	if p.features == nil {
+		var parent *descriptorpb.FeatureSet
+		if o := p.Oneof(); o != nil && !o.IsSynthetic() {
+			parent = o.Features()
+		} else {
+			parent = p.msg.Features()
+		}
+
+		out := mergeFeatures(parent, p.dp.GetOptions().GetFeatures())
+		if p.File().Syntax() != "editions" {
+			p.applyLegacyFeatures(out)
+		}
+		p.features = out
+	}
+	return p.features
+}
+
+func (p *Field) applyLegacyFeatures(out *descriptorpb.FeatureSet) {
+	switch {
+	case p.IsRequired():
+		out.FieldPresence = descriptorpb.FeatureSet_LEGACY_REQUIRED.Enum()
+	case p.IsProto3Optional():
+		out.FieldPresence = descriptorpb.FeatureSet_EXPLICIT.Enum()
+	}
+
+	if p.Type() == descriptorpb.FieldDescriptorProto_TYPE_GROUP {
+		out.MessageEncoding = descriptorpb.FeatureSet_DELIMITED.Enum()
+	}
+
+	if opts := p.dp.GetOptions(); opts != nil && opts.Packed != nil {
+		if opts.GetPacked() {
+			out.RepeatedFieldEncoding = descriptorpb.FeatureSet_PACKED.Enum()
+		} else {
+			out.RepeatedFieldEncoding = descriptorpb.FeatureSet_EXPANDED.Enum()
+		}
+	}
+}
+
+// Features returns the resolved [descriptorpb.FeatureSet] of the enum,
+// inherited from its parent
+func (p *Enum) Features() *descriptorpb.FeatureSet {
+	if p.features == nil {
+		var parent *descriptorpb.FeatureSet
+		if p.msg != nil {
+			parent = p.msg.Features()
+		} else {
+			parent = p.file.Features()
+		}
+
+		p.features = mergeFeatures(parent, p.dp.GetOptions().GetFeatures())
+	}
+	return p.features
+}
+
+// IsClosed tells if the enum rejects unknown values
+func (p *Enum) IsClosed() bool {
+	return p.Features().GetEnumType() == descriptorpb.FeatureSet_CLOSED
+}
+
+// IsDelimited tells if the message field uses the group wire encoding
+func (p *Field) IsDelimited() bool {
+	switch p.Type() {
+	case descriptorpb.FieldDescriptorProto_TYPE_MESSAGE,
+		descriptorpb.FieldDescriptorProto_TYPE_GROUP:
+		return p.Features().GetMessageEncoding() == descriptorpb.FeatureSet_DELIMITED
+	default:
+		return false
+	}
+}
+
+// ValidatesUTF8 tells if the string field must be valid UTF-8
+func (p *Field) ValidatesUTF8() bool {
+	return p.Type() == descriptorpb.FieldDescriptorProto_TYPE_STRING &&
+		p.Features().GetUtf8Validation() == descriptorpb.FeatureSet_VERIFY
+}
@@ -0,0 +1,346 @@
+package protogen
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+type (
+	fieldLabel = descriptorpb.FieldDescriptorProto_Label
+	fieldType  = descriptorpb.FieldDescriptorProto_Type
+	featureSet = descriptorpb.FeatureSet
+)
+
+const (
+	labelOptional = descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	labelRequired = descriptorpb.FieldDescriptorProto_LABEL_REQUIRED
+	labelRepeated = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+
+	typeInt32   = descriptorpb.FieldDescriptorProto_TYPE_INT32
+	typeString  = descriptorpb.FieldDescriptorProto_TYPE_STRING
+	typeMessage = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+	typeGroup   = descriptorpb.FieldDescriptorProto_TYPE_GROUP
+)
+
+func newTestField(name string, number int32, label fieldLabel, typ fieldType) *descriptorpb.FieldDescriptorProto {
+	return &descriptorpb.FieldDescriptorProto{
+		Name:   proto.String(name),
+		Number: proto.Int32(number),
+		Label:  label.Enum(),
+		Type:   typ.Enum(),
+	}
+}
+
+// withTypeName sets the type of a message, enum or group field
+func withTypeName(fd *descriptorpb.FieldDescriptorProto, name string) *descriptorpb.FieldDescriptorProto {
+	fd.TypeName = proto.String(name)
+	return fd
+}
+
+// withOneof makes the field member of a oneof
+func withOneof(fd *descriptorpb.FieldDescriptorProto, index int32,
+	proto3Optional bool) *descriptorpb.FieldDescriptorProto {
+	fd.OneofIndex = proto.Int32(index)
+	fd.Proto3Optional = PointerOrNil(proto3Optional)
+	return fd
+}
+
+// withFieldOptions sets the packed option and the features of a field
+func withFieldOptions(fd *descriptorpb.FieldDescriptorProto, packed *bool,
+	features *featureSet) *descriptorpb.FieldDescriptorProto {
+	fd.Options = &descriptorpb.FieldOptions{
+		Packed:   packed,
+		Features: features,
+	}
+	return fd
+}
+
+// newFeaturesTestFiles returns a proto2, a proto3 and an edition 2023
+// file, covering the legacy syntax translation and features inheritance
+func newFeaturesTestFiles() []*descriptorpb.FileDescriptorProto {
+	proto2 := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("legacy2.proto"),
+		Package: proto.String("p2"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("M"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					newTestField("a", 1, labelOptional, typeInt32),
+					newTestField("b", 2, labelRequired, typeInt32),
+					withFieldOptions(newTestField("c", 3, labelRepeated, typeInt32), proto.Bool(true), nil),
+					newTestField("d", 4, labelRepeated, typeInt32),
+					withTypeName(newTestField("e", 5, labelOptional, typeGroup), ".p2.M.E"),
+					newTestField("s", 6, labelOptional, typeString),
+				},
+				NestedType: []*descriptorpb.DescriptorProto{
+					{Name: proto.String("E")},
+				},
+			},
+		},
+	}
+
+	proto3 := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("legacy3.proto"),
+		Package: proto.String("p3"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("M"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					newTestField("a", 1, labelOptional, typeInt32),
+					withOneof(newTestField("b", 2, labelOptional, typeInt32), 1, true),
+					newTestField("c", 3, labelRepeated, typeInt32),
+					withFieldOptions(newTestField("d", 4, labelRepeated, typeInt32), proto.Bool(false), nil),
+					withOneof(newTestField("s", 5, labelOptional, typeString), 0, false),
+				},
+				OneofDecl: []*descriptorpb.OneofDescriptorProto{
+					{Name: proto.String("o")},
+					{Name: proto.String("_b")},
+				},
+			},
+		},
+	}
+
+	edition := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("edition.proto"),
+		Package: proto.String("ed"),
+		Syntax:  proto.String("editions"),
+		Edition: descriptorpb.Edition_EDITION_2023.Enum(),
+		Options: &descriptorpb.FileOptions{
+			Features: &featureSet{
+				FieldPresence: descriptorpb.FeatureSet_IMPLICIT.Enum(),
+			},
+		},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("M"),
+				Options: &descriptorpb.MessageOptions{
+					Features: &featureSet{
+						RepeatedFieldEncoding: descriptorpb.FeatureSet_EXPANDED.Enum(),
+					},
+				},
+				Field: []*descriptorpb.FieldDescriptorProto{
+					newTestField("a", 1, labelOptional, typeInt32),
+					withFieldOptions(newTestField("b", 2, labelOptional, typeInt32), nil, &featureSet{
+						FieldPresence: descriptorpb.FeatureSet_LEGACY_REQUIRED.Enum(),
+					}),
+					newTestField("c", 3, labelRepeated, typeInt32),
+					withFieldOptions(newTestField("d", 4, labelRepeated, typeInt32), nil, &featureSet{
+						RepeatedFieldEncoding: descriptorpb.FeatureSet_PACKED.Enum(),
+					}),
+					withFieldOptions(withTypeName(newTestField("e", 5, labelOptional, typeMessage), ".ed.M"),
+						nil, &featureSet{
+							MessageEncoding: descriptorpb.FeatureSet_DELIMITED.Enum(),
+						}),
+					withOneof(newTestField("s", 6, labelOptional, typeString), 0, false),
+					newTestField("t", 7, labelOptional, typeString),
+				},
+				OneofDecl: []*descriptorpb.OneofDescriptorProto{
+					{
+						Name: proto.String("o"),
+						Options: &descriptorpb.OneofOptions{
+							Features: &featureSet{
+								Utf8Validation: descriptorpb.FeatureSet_NONE.Enum(),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	return []*descriptorpb.FileDescriptorProto{proto2, proto3, edition}
+}
+
+func newFeaturesTestPlugin(t *testing.T) *Plugin {
+	t.Helper()
+
+	files := newFeaturesTestFiles()
+
+	req := &pluginpb.CodeGeneratorRequest{ProtoFile: files}
+	for _, f := range files {
+		req.FileToGenerate = append(req.FileToGenerate, f.GetName())
+	}
+
+	opts := &Options{
+		MaximumEdition: descriptorpb.Edition_EDITION_2023,
+	}
+
+	gen, err := NewPlugin(opts, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gen
+}
+
+func TestFileEdition(t *testing.T) {
+	gen := newFeaturesTestPlugin(t)
+
+	tests := []struct {
+		file     string
+		syntax   string
+		edition  descriptorpb.Edition
+		presence descriptorpb.FeatureSet_FieldPresence
+		enumType descriptorpb.FeatureSet_EnumType
+	}{
+		{"legacy2.proto", "proto2", descriptorpb.Edition_EDITION_PROTO2,
+			descriptorpb.FeatureSet_EXPLICIT, descriptorpb.FeatureSet_CLOSED},
+		{"legacy3.proto", "proto3", descriptorpb.Edition_EDITION_PROTO3,
+			descriptorpb.FeatureSet_IMPLICIT, descriptorpb.FeatureSet_OPEN},
+		{"edition.proto", "editions", descriptorpb.Edition_EDITION_2023,
+			descriptorpb.FeatureSet_IMPLICIT, descriptorpb.FeatureSet_OPEN},
+	}
+
+	for _, tc := range tests {
+		f := gen.FileByName(tc.file)
+		features := f.Features()
+
+		switch {
+		case f.Syntax() != tc.syntax:
+			t.Errorf("%s: Syntax() = %q, expected %q", tc.file, f.Syntax(), tc.syntax)
+		case f.Edition() != tc.edition:
+			t.Errorf("%s: Edition() = %s, expected %s", tc.file, f.Edition(), tc.edition)
+		case features.GetFieldPresence() != tc.presence:
+			t.Errorf("%s: field_presence = %s, expected %s", tc.file,
+				features.GetFieldPresence(), tc.presence)
+		case features.GetEnumType() != tc.enumType:
+			t.Errorf("%s: enum_type = %s, expected %s", tc.file,
+				features.GetEnumType(), tc.enumType)
+		}
+	}
+}
+
+type fieldFeaturesCase struct {
+	file, field string
+
+	cardinality protoreflect.Cardinality
+	presence    descriptorpb.FeatureSet_FieldPresence
+	hasPresence bool
+	packed      bool
+	delimited   bool
+	utf8        bool
+}
+
+func (tc *fieldFeaturesCase) check(t *testing.T, p *Field) {
+	t.Helper()
+
+	if got := p.Cardinality(); got != tc.cardinality {
+		t.Errorf("Cardinality() = %s, expected %s", got, tc.cardinality)
+	}
+	if got := p.Features().GetFieldPresence(); got != tc.presence {
+		t.Errorf("field_presence = %s, expected %s", got, tc.presence)
+	}
+	if got := p.HasPresence(); got != tc.hasPresence {
+		t.Errorf("HasPresence() = %v, expected %v", got, tc.hasPresence)
+	}
+	if got := p.IsPacked(); got != tc.packed {
+		t.Errorf("IsPacked() = %v, expected %v", got, tc.packed)
+	}
+	if got := p.IsDelimited(); got != tc.delimited {
+		t.Errorf("IsDelimited() = %v, expected %v", got, tc.delimited)
+	}
+	if got := p.ValidatesUTF8(); got != tc.utf8 {
+		t.Errorf("ValidatesUTF8() = %v, expected %v", got, tc.utf8)
+	}
+}
+
+func TestFieldFeatures(t *testing.T) {
+	const (
+		optional = protoreflect.Optional
+		required = protoreflect.Required
+		repeated = protoreflect.Repeated
+
+		explicit       = descriptorpb.FeatureSet_EXPLICIT
+		implicit       = descriptorpb.FeatureSet_IMPLICIT
+		legacyRequired = descriptorpb.FeatureSet_LEGACY_REQUIRED
+	)
+
+	tests := []fieldFeaturesCase{
+		// proto2
+		{"legacy2.proto", "a", optional, explicit, true, false, false, false},
+		{"legacy2.proto", "b", required, legacyRequired, true, false, false, false},
+		{"legacy2.proto", "c", repeated, explicit, false, true, false, false},
+		{"legacy2.proto", "d", repeated, explicit, false, false, false, false},
+		{"legacy2.proto", "e", optional, explicit, true, false, true, false},
+		{"legacy2.proto", "s", optional, explicit, true, false, false, false},
+		// proto3
+		{"legacy3.proto", "a", optional, implicit, false, false, false, false},
+		{"legacy3.proto", "b", optional, explicit, true, false, false, false},
+		{"legacy3.proto", "c", repeated, implicit, false, true, false, false},
+		{"legacy3.proto", "d", repeated, implicit, false, false, false, false},
+		{"legacy3.proto", "s", optional, implicit, true, false, false, true},
+		// editions: file -> message -> oneof -> field
+		{"edition.proto", "a", optional, implicit, false, false, false, false},
+		{"edition.proto", "b", required, legacyRequired, true, false, false, false},
+		{"edition.proto", "c", repeated, implicit, false, false, false, false},
+		{"edition.proto", "d", repeated, implicit, false, true, false, false},
+		{"edition.proto", "e", optional, implicit, true, false, true, false},
+		{"edition.proto", "s", optional, implicit, true, false, false, false},
+		{"edition.proto", "t", optional, implicit, false, false, false, true},
+	}
+
+	gen := newFeaturesTestPlugin(t)
+	for i := range tests {
+		tc := &tests[i]
+		t.Run(tc.file+":"+tc.field, func(t *testing.T) {
+			msg := gen.FileByName(tc.file).MessageByName("M")
+			tc.check(t, msg.FieldByName(tc.field))
+		})
+	}
+}
+
+func TestSupportsEdition(t *testing.T) {
+	const (
+		proto2 = descriptorpb.Edition_EDITION_PROTO2
+		proto3 = descriptorpb.Edition_EDITION_PROTO3
+		e2023  = descriptorpb.Edition_EDITION_2023
+		e2024  = descriptorpb.Edition_EDITION_2024
+	)
+
+	tests := []struct {
+		name string
+		opts Options
+		want map[descriptorpb.Edition]bool
+	}{
+		{
+			name: "legacy only",
+			want: map[descriptorpb.Edition]bool{proto2: true, proto3: true, e2023: false},
+		},
+		{
+			name: "feature without range",
+			opts: Options{Features: pluginpb.CodeGeneratorResponse_FEATURE_SUPPORTS_EDITIONS},
+			want: map[descriptorpb.Edition]bool{proto2: true, e2023: true, e2024: false},
+		},
+		{
+			name: "range",
+			opts: Options{MaximumEdition: e2024},
+			want: map[descriptorpb.Edition]bool{proto3: true, e2023: true, e2024: true},
+		},
+		{
+			name: "minimum",
+			opts: Options{MinimumEdition: e2024, MaximumEdition: e2024},
+			want: map[descriptorpb.Edition]bool{proto2: true, e2023: false, e2024: true},
+		},
+	}
+
+	for _, tc := range tests {
+		opts := tc.opts
+		t.Run(tc.name, func(t *testing.T) {
+			checkSupportsEdition(t, &opts, tc.want)
+		})
+	}
+}
+
+func checkSupportsEdition(t *testing.T, opts *Options, want map[descriptorpb.Edition]bool) {
+	opts.SetDefaults()
+
+	for edition, ok := range want {
+		if got := opts.SupportsEdition(edition); got != ok {
+			t.Errorf("SupportsEdition(%s) = %v, expected %v", edition, got, ok)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package protogen
+
+import (
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	"github.com/amery/protogen/pkg/protogen/naming"
+)
+
+var (
+	_ ProtoTyper = (*Field)(nil)
+)
+
+// Field represents a field of a [Message]
+type Field struct {
+	msg   *Message
+	dp    *descriptorpb.FieldDescriptorProto
+	index int
+
+	resolved ProtoTyper
+	features *descriptorpb.FeatureSet
+}
+
+// Request returns the [pluginpb.CodeGeneratorRequest] received by
+// the [Plugin]
+func (p *Field) Request() *pluginpb.CodeGeneratorRequest {
+	return p.msg.Request()
+}
+
+// Proto returns the underlying protobuf structure
+func (p *Field) Proto() *descriptorpb.FieldDescriptorProto {
+	return p.dp
+}
+
+// File returns the [File] that defines this field
+func (p *Field) File() *File {
+	return p.msg.File()
+}
+
+// Package returns the package name associated to this field
+func (p *Field) Package() string {
+	return p.msg.Package()
+}
+
+// Parent returns the [Message] this field belongs to
+func (p *Field) Parent() *Message {
+	return p.msg
+}
+
+// Name returns the relative name of this field
+func (p *Field) Name() string {
+	return optional(p.dp.Name, "")
+}
+
+// FullName returns the fully qualified name of this field
+func (p *Field) FullName() string {
+	s0 := p.msg.FullName()
+	s1 := p.Name()
+
+	switch {
+	case s0 != "" && s1 != "":
+		return s0 + "." + s1
+	default:
+		return s1
+	}
+}
+
+// Number returns the field number
+func (p *Field) Number() int {
+	return int(optional(p.dp.Number, 0))
+}
+
+// Label returns the declared label of the field
+func (p *Field) Label() descriptorpb.FieldDescriptorProto_Label {
+	return optional(p.dp.Label, descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL)
+}
+
+// Cardinality returns the cardinality of the field
+func (p *Field) Cardinality() protoreflect.Cardinality {
+	switch {
+	case p.Label() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED:
+		return protoreflect.Repeated
+	case p.Label() == descriptorpb.FieldDescriptorProto_LABEL_REQUIRED:
+		return protoreflect.Required
+	case p.File().Syntax() == "editions" &&
+		p.Features().GetFieldPresence() == descriptorpb.FeatureSet_LEGACY_REQUIRED:
+		return protoreflect.Required
+	default:
+		return protoreflect.Optional
+	}
+}
+
+// IsRepeated tells if the field is a list or a map
+func (p *Field) IsRepeated() bool {
+	return p.Cardinality() == protoreflect.Repeated
+}
+
+// IsRequired tells if the field was declared as proto2 required
+func (p *Field) IsRequired() bool {
+	return p.Cardinality() == protoreflect.Required
+}
+
+// Type returns the type of the field
+func (p *Field) Type() descriptorpb.FieldDescriptorProto_Type {
+	return optional(p.dp.Type, 0)
+}
+
+// TypeName returns the fully qualified name of the [Message] or [Enum]
+// used by this field, if any, as given by protoc. e.g. ".foo.Bar"
+func (p *Field) TypeName() string {
+	return optional(p.dp.TypeName, "")
+}
+
+// JSONName returns the name used to represent this field in JSON,
+// either as given by protoc or computed from the field's name
+func (p *Field) JSONName() string {
+	if s, ok := optional2(p.dp.JsonName, ""); ok {
+		return s
+	}
+	return naming.JSONName(p.Name())
+}
+
+// DefaultValue returns the textual representation of the default
+// value of the field, and if one was explicitly declared.
+func (p *Field) DefaultValue() (string, bool) {
+	return optional2(p.dp.DefaultValue, "")
+}
+
+// IsPacked tells if the repeated field uses the packed wire encoding,
+// either because it was explicitly requested or because it's the
+// default for scalar numeric types on the file's edition.
+func (p *Field) IsPacked() bool {
+	switch {
+	case !p.IsRepeated() || !isPackable(p.Type()):
+		return false
+	default:
+		return p.Features().GetRepeatedFieldEncoding() == descriptorpb.FeatureSet_PACKED
+	}
+}
+
+func isPackable(t descriptorpb.FieldDescriptorProto_Type) bool {
+	switch t {
+	case descriptorpb.FieldDescriptorProto_TYPE_STRING,
+		descriptorpb.FieldDescriptorProto_TYPE_BYTES,
+		descriptorpb.FieldDescriptorProto_TYPE_MESSAGE,
+		descriptorpb.FieldDescriptorProto_TYPE_GROUP:
+		return false
+	default:
+		return t != 0
+	}
+}
+
+// Fields returns all the [Field]s of this message
+func (p *Message) Fields() []*Field {
+	if p.fields == nil {
+		p.loadFields()
+	}
+	return p.fields
+}
+
+// FieldByName finds a [Field] by name
+func (p *Message) FieldByName(name string) *Field {
+	for _, f := range p.Fields() {
+		if name == f.Name() {
+			// match
+			return f
+		}
+	}
+	return nil
+}
+
+// FieldByNumber finds a [Field] by number
+func (p *Message) FieldByNumber(n int) *Field {
+	for _, f := range p.Fields() {
+		if n == f.Number() {
+			// match
+			return f
+		}
+	}
+	return nil
+}
+
+func (p *Message) loadFields() {
+	out := make([]*Field, 0, len(p.dp.Field))
+	for i, dp := range p.dp.Field {
+		if dp == nil {
+			continue
+		}
+
+		f := &Field{
+			msg:   p,
+			dp:    dp,
+			index: i,
+		}
+
+		out = append(out, f)
+	}
+
+	// sort fields by number
+	Sort(out, func(a, b *Field) bool {
+		return a.Number() < b.Number()
+	})
+
+	p.fields = out
+}
@@ -15,10 +15,13 @@ type File struct {
 	gen *Plugin
 	dp  *descriptorpb.FileDescriptorProto
 
-	generate bool
+	generate  bool
+	locations map[string]*descriptorpb.SourceCodeInfo_Location
 
 	enums    []*Enum
 	messages []*Message
+	services []*Service
+	features *descriptorpb.FeatureSet
 }
 
 // Request returns the [pluginpb.CodeGeneratorRequest] received by
@@ -81,6 +84,15 @@ func (f *File) Package() string {
 	return optional(f.dp.Package, "")
 }
 
+// Syntax returns the syntax of the proto file, "proto2", "proto3"
+// or "editions"
+func (f *File) Syntax() string {
+	if s := optional(f.dp.Syntax, ""); s != "" {
+		return s
+	}
+	return "proto2"
+}
+
 // PackageDirectory returns the package name associated to this file
 // converted to a directory path
 func (f *File) PackageDirectory() string {
@@ -136,6 +148,7 @@ func (gen *Plugin) loadFiles(files ...*descriptorpb.FileDescriptorProto) {
 			gen: gen,
 		}
 
+		f.loadLocations()
 		gen.files = append(gen.files, f)
 	}
 }
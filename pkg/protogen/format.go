@@ -0,0 +1,145 @@
+package protogen
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Formatter post-processes the content of a generated file
+// before it's added to the response
+type Formatter func(name string, content []byte) ([]byte, error)
+
+// GoFormatter formats Go source code using [go/format]
+func GoFormatter(_ string, content []byte) ([]byte, error) {
+	return format.Source(content)
+}
+
+// JSONFormatter pretty-prints JSON content with two spaces indentation
+func JSONFormatter(_ string, content []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	content = bytes.TrimRightFunc(content, unicode.IsSpace)
+	if err := json.Indent(&buf, content, "", "  "); err != nil {
+		return nil, err
+	}
+
+	_ = buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// FormatError is the failure of a [Formatter] over a generated
+// file, including the offending content
+type FormatError struct {
+	Name    string
+	Content []byte
+	Err     error
+}
+
+func (e FormatError) Error() string {
+	var buf strings.Builder
+
+	_, _ = fmt.Fprintf(&buf, "%s: %s\n", e.Name, e.Err)
+
+	// numbered source
+	scanner := bufio.NewScanner(bytes.NewReader(e.Content))
+	scanner.Buffer(nil, len(e.Content)+1)
+	for i := 1; scanner.Scan(); i++ {
+		_, _ = fmt.Fprintf(&buf, "%5d\t%s\n", i, scanner.Text())
+	}
+
+	return buf.String()
+}
+
+func (e FormatError) Unwrap() error {
+	return e.Err
+}
+
+// formatter finds the [Formatter] for a file name by extension
+func (opts *Options) formatter(name string) Formatter {
+	ext := filepath.Ext(name)
+	if ext == "" {
+		return nil
+	}
+	return opts.Formatters[ext]
+}
+
+// SetFormatter registers a [Formatter] for files of the
+// given extension, e.g. ".go"
+func (opts *Options) SetFormatter(ext string, fn Formatter) {
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+
+	if opts.Formatters == nil {
+		opts.Formatters = make(map[string]Formatter)
+	}
+
+	if fn == nil {
+		delete(opts.Formatters, ext)
+	} else {
+		opts.Formatters[ext] = fn
+	}
+}
+
+func (f *GeneratedFile) format() error {
+	fn := f.gen.options.formatter(f.name)
+	if fn == nil || f.point != "" {
+		// insertion points are fragments
+		return nil
+	}
+
+	content := f.buf.Bytes()
+	out, err := fn(f.name, content)
+	if err != nil {
+		return &FormatError{
+			Name:    f.name,
+			Content: content,
+			Err:     err,
+		}
+	}
+
+	if !bytes.Equal(out, content) {
+		f.remapAnnotations(content, out)
+	}
+
+	f.buf = bytes.NewBuffer(out)
+	return nil
+}
+
+// remapAnnotations finds the annotated snippets of the original content
+// on the formatted one, in order, and drops those that can't be found.
+func (f *GeneratedFile) remapAnnotations(before, after []byte) {
+	Sort(f.annotations, func(a, b *descriptorpb.GeneratedCodeInfo_Annotation) bool {
+		return a.GetBegin() < b.GetBegin()
+	})
+
+	var cursor int
+	out := f.annotations[:0]
+	for _, a := range f.annotations {
+		begin, end := int(a.GetBegin()), int(a.GetEnd())
+		if end > len(before) {
+			continue
+		}
+
+		snippet := before[begin:end]
+		i := bytes.Index(after[cursor:], snippet)
+		if i < 0 {
+			continue
+		}
+
+		cursor += i
+		a.Begin = proto.Int32(int32(cursor))
+		a.End = proto.Int32(int32(cursor + len(snippet)))
+		out = append(out, a)
+	}
+	f.annotations = out
+}
@@ -12,7 +12,10 @@ import (
 	"unicode/utf8"
 
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
 	"google.golang.org/protobuf/types/pluginpb"
+
+	"github.com/amery/protogen/pkg/protogen/naming"
 )
 
 var (
@@ -22,9 +25,21 @@ var (
 
 // GeneratedFile implements GeneratedFile
 type GeneratedFile struct {
-	gen  *Plugin
-	buf  *bytes.Buffer
-	name string
+	gen   *Plugin
+	buf   *bytes.Buffer
+	name  string
+	point string
+
+	annotations []*descriptorpb.GeneratedCodeInfo_Annotation
+
+	imports   ImportManager
+	importsAt int // -1 unless marked by ImportsHere
+	names     *naming.Allocator
+
+	indent         string
+	level          int
+	bol            bool
+	noFinalNewline bool
 }
 
 // Name returns the output name associated to this file
@@ -32,10 +47,49 @@ func (f *GeneratedFile) Name() string {
 	return f.name
 }
 
+// InsertionPoint returns the name of the insertion point this
+// content is meant for, or empty if it's a whole file
+func (f *GeneratedFile) InsertionPoint() string {
+	return f.point
+}
+
+// Names returns the [naming.Allocator] of identifiers used on this file,
+// escaping the reserved words of the language guessed by the
+// file's extension unless set via [GeneratedFile.SetNames]
+func (f *GeneratedFile) Names() *naming.Allocator {
+	if f.names == nil {
+		f.names = naming.NewAllocator(naming.LanguageByExtension(f.name))
+	}
+	return f.names
+}
+
+// SetNames sets the [naming.Allocator] of identifiers used on this file
+func (f *GeneratedFile) SetNames(names *naming.Allocator) {
+	f.names = names
+}
+
+// generatedKey identifies a [GeneratedFile] on the plugin
+type generatedKey struct {
+	name  string
+	point string
+}
+
+func (f *GeneratedFile) key() generatedKey {
+	return generatedKey{f.name, f.point}
+}
+
+// generatedPath describes a file or insertion point for errors
+func generatedPath(name, point string) string {
+	if point == "" {
+		return name
+	}
+	return name + "@" + point
+}
+
 // P adds content in the way of fmt.Print, not inserting space between
 // arguments
 func (f *GeneratedFile) P(values ...any) {
-	_, err := fmt.Fprint(f.buf, values...)
+	_, err := fmt.Fprint(f, values...)
 	if err != nil {
 		panic(err)
 	}
@@ -43,7 +97,7 @@ func (f *GeneratedFile) P(values ...any) {
 
 // F adds formatted content in the way of fmt.Printf
 func (f *GeneratedFile) F(format string, args ...any) {
-	_, err := fmt.Fprintf(f.buf, format, args...)
+	_, err := fmt.Fprintf(f, format, args...)
 	if err != nil {
 		panic(err)
 	}
@@ -51,10 +105,11 @@ func (f *GeneratedFile) F(format string, args ...any) {
 
 // T executes a template over the buffer
 func (f *GeneratedFile) T(t *template.Template, data any) error {
-	return t.Execute(f.buf, data)
+	return t.Execute(f, data)
 }
 
-// Write writes content to the file
+// Write writes content to the file, indenting every
+// non-empty line
 func (f *GeneratedFile) Write(b []byte) (int, error) {
 	switch {
 	case f == nil:
@@ -62,7 +117,8 @@ func (f *GeneratedFile) Write(b []byte) (int, error) {
 	case f.buf == nil:
 		return 0, fs.ErrClosed
 	default:
-		return f.buf.Write(b)
+		f.writeIndented(b)
+		return len(b), nil
 	}
 }
 
@@ -80,18 +136,26 @@ func (f *GeneratedFile) Close() error {
 
 func (gen *Plugin) saveGenerated(f *GeneratedFile) error {
 	// double check we are the right instance
-	f0, ok := gen.generated[f.name]
+	f0, ok := gen.generated[f.key()]
 	if !ok || f0 != f {
 		return fs.ErrInvalid
 	}
 
 	defer func() {
 		// remove regardless the outcome
-		delete(f.gen.generated, f.name)
+		delete(f.gen.generated, f.key())
 		f.buf = nil
 	}()
 
 	// content
+	f.addFinalNewline()
+	if err := f.renderImports(); err != nil {
+		return err
+	}
+	if err := f.format(); err != nil {
+		return err
+	}
+
 	s := f.buf.String()
 	if !utf8.ValidString(s) {
 		return Wrap(ErrInvalidUTF8Content, f.name)
@@ -99,8 +163,10 @@ func (gen *Plugin) saveGenerated(f *GeneratedFile) error {
 
 	// append to response
 	g := &pluginpb.CodeGeneratorResponse_File{
-		Name:    proto.String(f.name),
-		Content: proto.String(s),
+		Name:              proto.String(f.name),
+		InsertionPoint:    PointerOrNil(f.point),
+		Content:           proto.String(s),
+		GeneratedCodeInfo: f.generatedCodeInfo(),
 	}
 
 	gen.resp.File = append(gen.resp.File, g)
@@ -121,47 +187,101 @@ func (f *GeneratedFile) Discard() error {
 
 func (gen *Plugin) discardGenerated(f *GeneratedFile) error {
 	// double check we are the right instance
-	f0, ok := gen.generated[f.name]
+	f0, ok := gen.generated[f.key()]
 	if !ok || f0 != f {
 		return fs.ErrInvalid
 	}
 
 	// purge buffer and discard
-	delete(gen.generated, f.name)
+	delete(gen.generated, f.key())
 	f.buf = nil
 	return nil
 }
 
 // NewGeneratedFile creates a new output file
 func (gen *Plugin) NewGeneratedFile(format string, args ...any) (*GeneratedFile, error) {
-	var err error
-
 	name, ok := getGeneratedName(format, args...)
 	if !ok {
-		err = ErrInvalidName
-	} else if _, ok = gen.generated[name]; ok {
-		err = fs.ErrExist
-	}
-
-	if err != nil {
 		return nil, &fs.PathError{
 			Op:   "create",
 			Path: name,
-			Err:  err,
+			Err:  ErrInvalidName,
+		}
+	}
+
+	return gen.newGenerated("create", name, "")
+}
+
+// NewInsertionPoint creates new content to be inserted by protoc into
+// a file generated by another plugin at the line marked with
+// @@protoc_insertion_point(pointName)
+func (gen *Plugin) NewInsertionPoint(fileName, pointName string) (*GeneratedFile, error) {
+	name, ok := getGeneratedName(fileName)
+	if ok {
+		ok = validInsertionPoint(pointName)
+	}
+
+	if !ok {
+		return nil, &fs.PathError{
+			Op:   "insert",
+			Path: generatedPath(name, pointName),
+			Err:  ErrInvalidName,
+		}
+	}
+
+	return gen.newGenerated("insert", name, pointName)
+}
+
+func (gen *Plugin) newGenerated(op, name, point string) (*GeneratedFile, error) {
+	key := generatedKey{name, point}
+	if _, ok := gen.generated[key]; ok {
+		return nil, &fs.PathError{
+			Op:   op,
+			Path: generatedPath(name, point),
+			Err:  fs.ErrExist,
 		}
 	}
 
 	f := &GeneratedFile{
-		gen:  gen,
-		buf:  new(bytes.Buffer),
-		name: name,
+		gen:    gen,
+		buf:    new(bytes.Buffer),
+		name:   name,
+		point:  point,
+		indent: gen.options.Indent,
+		bol:    true,
+
+		importsAt: -1,
 	}
 
-	gen.generated[name] = f
+	gen.generated[key] = f
 
 	return f, nil
 }
 
+func validInsertionPoint(s string) bool {
+	return s != "" && !strings.ContainsAny(s, "()\r\n")
+}
+
+// WriteInsertionPoint writes a @@protoc_insertion_point(name) marker
+// so other plugins can insert content at this line
+func (f *GeneratedFile) WriteInsertionPoint(style CommentStyle, name string) error {
+	if !validInsertionPoint(name) {
+		return &fs.PathError{
+			Op:   "mark",
+			Path: generatedPath(f.name, name),
+			Err:  ErrInvalidName,
+		}
+	}
+
+	marker := "@@protoc_insertion_point(" + name + ")"
+	if style == BlockComments {
+		f.P("/* ", marker, " */\n")
+	} else {
+		f.WriteComment(style, " "+marker)
+	}
+	return nil
+}
+
 func getGeneratedName(s string, args ...any) (string, bool) {
 	if len(args) > 0 {
 		s = fmt.Sprintf(s, args...)
@@ -174,6 +294,9 @@ func getGeneratedName(s string, args ...any) (string, bool) {
 		return s, false
 	case strings.ContainsRune(s, '\\'):
 		return s, false
+	case s == "..", strings.HasPrefix(s, "../"):
+		// escapes the output directory
+		return s, false
 	default:
 		return s, true
 	}
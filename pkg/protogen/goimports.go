@@ -0,0 +1,158 @@
+package protogen
+
+import (
+	"bytes"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/amery/protogen/pkg/protogen/naming"
+)
+
+var (
+	_ ImportManager  = (*GoImports)(nil)
+	_ ImportsLocator = (*GoImports)(nil)
+)
+
+// GoImports is an [ImportManager] for Go source files
+type GoImports struct {
+	self    string
+	aliases map[string]string // path -> alias
+	paths   map[string]string // alias -> path
+}
+
+// NewGoImports creates a [GoImports] for a file belonging to the
+// package of the given import path. Symbols of the own package
+// aren't qualified.
+func NewGoImports(packagePath string) *GoImports {
+	return &GoImports{
+		self:    packagePath,
+		aliases: make(map[string]string),
+		paths:   make(map[string]string),
+	}
+}
+
+// Import records a dependency on a Go package and returns the
+// name to use for it, aliasing it if needed to avoid conflicts.
+func (m *GoImports) Import(importPath string) string {
+	if importPath == "" || importPath == m.self {
+		return ""
+	}
+
+	if alias, ok := m.aliases[importPath]; ok {
+		return alias
+	}
+
+	base := goPackageName(importPath)
+	alias := base
+	for i := 1; m.paths[alias] != ""; i++ {
+		alias = base + strconv.Itoa(i)
+	}
+
+	m.aliases[importPath] = alias
+	m.paths[alias] = importPath
+	return alias
+}
+
+// Render returns the import declaration, with the standard library
+// packages grouped first
+func (m *GoImports) Render() []byte {
+	if len(m.aliases) == 0 {
+		return nil
+	}
+
+	var std, others []string
+	for p := range m.aliases {
+		if isGoStdPackage(p) {
+			std = append(std, p)
+		} else {
+			others = append(others, p)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(others)
+
+	var buf bytes.Buffer
+	_, _ = buf.WriteString("import (\n")
+	m.renderGroup(&buf, std)
+	if len(std) > 0 && len(others) > 0 {
+		_ = buf.WriteByte('\n')
+	}
+	m.renderGroup(&buf, others)
+	_, _ = buf.WriteString(")\n\n")
+
+	return buf.Bytes()
+}
+
+// ImportsOffset returns the offset of the line following the
+// package clause, skipping one blank line, or -1 if the content
+// doesn't start with one.
+func (*GoImports) ImportsOffset(content []byte) int {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(content))
+
+	var s scanner.Scanner
+	s.Init(file, content, nil, 0)
+
+	var pos token.Pos
+	for _, want := range []token.Token{token.PACKAGE, token.IDENT, token.SEMICOLON} {
+		var tok token.Token
+		if pos, tok, _ = s.Scan(); tok != want {
+			return -1
+		}
+	}
+
+	// end of the line, after any trailing comment
+	at := file.Offset(pos)
+	i := bytes.IndexByte(content[at:], '\n')
+	if i < 0 {
+		return len(content)
+	}
+
+	at += i + 1
+	if at < len(content) && content[at] == '\n' {
+		at++
+	}
+	return at
+}
+
+func (m *GoImports) renderGroup(buf *bytes.Buffer, paths []string) {
+	for _, p := range paths {
+		alias := m.aliases[p]
+		if alias == path.Base(p) {
+			_, _ = fmt.Fprintf(buf, "\t%q\n", p)
+		} else {
+			_, _ = fmt.Fprintf(buf, "\t%s %q\n", alias, p)
+		}
+	}
+}
+
+// goPackageName guesses a valid package name from an import path,
+// skipping major version suffixes
+func goPackageName(importPath string) string {
+	base := path.Base(importPath)
+	if isGoMajorVersion(base) {
+		if dir := path.Dir(importPath); dir != "." {
+			base = path.Base(dir)
+		}
+	}
+
+	return naming.GoSanitized(base)
+}
+
+func isGoMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(s[1:])
+	return err == nil
+}
+
+func isGoStdPackage(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
@@ -0,0 +1,48 @@
+package protogen
+
+import (
+	"path"
+	"strings"
+)
+
+// goPackageOption splits the go_package option into import path
+// and package name
+func (f *File) goPackageOption() (importPath, name string) {
+	s := f.dp.GetOptions().GetGoPackage()
+	importPath, name, _ = strings.Cut(s, ";")
+	return importPath, name
+}
+
+// GoImportPath returns the Go import path of the file, taken from
+// a M<file>=<importpath> parameter or the go_package option
+func (f *File) GoImportPath() string {
+	if s, ok := f.gen.ImportPathOf(f.Name()); ok {
+		return s
+	}
+
+	s, _ := f.goPackageOption()
+	return s
+}
+
+// GoPackageName returns the Go package name of the file, explicitly
+// given on a M<file>=<importpath>;<name> parameter or the go_package
+// option, or derived from the import path, or from the proto package
+// if there is none
+func (f *File) GoPackageName() string {
+	importPath, name := f.goPackageOption()
+	if s, ok := f.gen.ImportPathOf(f.Name()); ok {
+		// mapped
+		importPath, name = s, f.gen.importNames[f.Name()]
+	}
+
+	switch {
+	case name != "":
+		return name
+	case importPath != "":
+		return goPackageName(importPath)
+	case f.Package() != "":
+		return goPackageName(strings.ReplaceAll(f.Package(), ".", "/"))
+	default:
+		return goPackageName(path.Base(f.Base()))
+	}
+}
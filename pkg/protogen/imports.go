@@ -0,0 +1,120 @@
+package protogen
+
+import (
+	"bytes"
+	"io/fs"
+)
+
+// ImportManager tracks the dependencies of a [GeneratedFile] in a
+// language specific way, and renders them when the file is closed.
+type ImportManager interface {
+	// Import records a dependency and returns the qualifier to use
+	// when referring to its symbols, if the language has one.
+	Import(path string) string
+	// Render returns the block of imports declarations, or nil if
+	// nothing was imported.
+	Render() []byte
+}
+
+// ImportsLocator is optionally implemented by an [ImportManager]
+// to tell where the imports go when [GeneratedFile.ImportsHere]
+// wasn't called.
+type ImportsLocator interface {
+	// ImportsOffset returns the offset within the given content
+	// where the rendered imports should be inserted, or -1 if
+	// there is no suitable place.
+	ImportsOffset(content []byte) int
+}
+
+// SetImports sets the [ImportManager] of this file. The rendered
+// imports will be inserted at the offset marked by
+// [GeneratedFile.ImportsHere], at the one chosen by an
+// [ImportsLocator], or at the beginning of the file.
+func (f *GeneratedFile) SetImports(m ImportManager) {
+	f.imports = m
+}
+
+// Imports returns the [ImportManager] of this file, if any
+func (f *GeneratedFile) Imports() ImportManager {
+	return f.imports
+}
+
+// ImportsHere marks the current offset as the place where the
+// rendered imports will be inserted
+func (f *GeneratedFile) ImportsHere() {
+	f.importsAt = f.Offset()
+}
+
+// Import records a dependency using the file's [ImportManager] and
+// returns the qualifier to use when referring to its symbols
+func (f *GeneratedFile) Import(path string) string {
+	if f.imports == nil {
+		return ""
+	}
+	return f.imports.Import(path)
+}
+
+// Symbol records a dependency on path and returns how to refer to
+// the named symbol from this file. e.g. "timestamppb.Timestamp"
+func (f *GeneratedFile) Symbol(path, name string) string {
+	if q := f.Import(path); q != "" {
+		return q + "." + name
+	}
+	return name
+}
+
+// renderImports inserts the rendered imports into the buffer,
+// moving the annotations after them accordingly
+func (f *GeneratedFile) renderImports() error {
+	if f.imports == nil {
+		return nil
+	}
+
+	block := f.imports.Render()
+	if len(block) == 0 {
+		return nil
+	}
+
+	at, ok := f.importsOffset()
+	if !ok {
+		return &fs.PathError{
+			Op:   "imports",
+			Path: generatedPath(f.name, f.point),
+			Err:  fs.ErrInvalid,
+		}
+	}
+
+	b := f.buf.Bytes()
+
+	var buf bytes.Buffer
+	buf.Grow(len(b) + len(block))
+	_, _ = buf.Write(b[:at])
+	_, _ = buf.Write(block)
+	_, _ = buf.Write(b[at:])
+
+	f.buf = &buf
+	f.shiftAnnotations(at, len(block))
+	return nil
+}
+
+// importsOffset tells where the rendered imports go, either
+// the marked offset, the one chosen by the [ImportsLocator] or
+// the beginning of the file.
+func (f *GeneratedFile) importsOffset() (offset int, ok bool) {
+	at := f.importsAt
+	if at < 0 {
+		at = 0
+		if l, ok := f.imports.(ImportsLocator); ok {
+			at = l.ImportsOffset(f.buf.Bytes())
+		}
+	}
+
+	switch {
+	case at < 0:
+		return 0, false
+	case at > f.buf.Len():
+		return f.buf.Len(), true
+	default:
+		return at, true
+	}
+}
@@ -0,0 +1,55 @@
+package protogen
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestGoImportsOffset(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{"package", "package foo\n\nvar x int\n", 13},
+		{"no blank line", "package foo\nvar x int\n", 12},
+		{"header", "// Code generated. DO NOT EDIT.\n\npackage foo\n", 45},
+		{"trailing comment", "package foo // bar\n\nvar x int\n", 20},
+		{"no newline", "package foo", 11},
+		{"no package", "var x int\n", -1},
+		{"empty", "", -1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := NewGoImports("foo").ImportsOffset([]byte(tc.content))
+			if got != tc.want {
+				t.Errorf("ImportsOffset(%q) = %v, expected %v", tc.content, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGoImportsWithoutMark(t *testing.T) {
+	gen, err := NewPlugin(nil, &pluginpb.CodeGeneratorRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := gen.NewGeneratedFile("foo.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f.SetImports(NewGoImports("example.com/foo"))
+	f.P("package foo\n\nvar x ", f.Symbol("time", "Duration"), "\n")
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	const want = "package foo\n\nimport (\n\t\"time\"\n)\n\nvar x time.Duration\n"
+	if got := gen.Response().File[0].GetContent(); got != want {
+		t.Errorf("content = %q, expected %q", got, want)
+	}
+}
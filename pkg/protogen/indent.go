@@ -0,0 +1,105 @@
+package protogen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Common indentation strings
+const (
+	IndentTab        = "\t"
+	IndentTwoSpaces  = "  "
+	IndentFourSpaces = "    "
+)
+
+// SetIndent sets the string used for each level of indentation
+func (f *GeneratedFile) SetIndent(indent string) {
+	f.indent = indent
+}
+
+// SetFinalNewline tells if a new line should be appended when
+// closing the file if the content doesn't end with one. Enabled
+// by default.
+func (f *GeneratedFile) SetFinalNewline(enabled bool) {
+	f.noFinalNewline = !enabled
+}
+
+// In increases the indentation level
+func (f *GeneratedFile) In() {
+	f.level++
+}
+
+// Out decreases the indentation level
+func (f *GeneratedFile) Out() {
+	if f.level > 0 {
+		f.level--
+	}
+}
+
+// Block writes the open line, calls fn with the indentation increased,
+// and then writes the close line. e.g.
+//
+//	f.Block("message Foo {", "}", func() {
+//		f.Ln("int32 bar = 1;")
+//	})
+func (f *GeneratedFile) Block(open, close string, fn func()) {
+	f.Ln(open)
+	f.In()
+	fn()
+	f.Out()
+	f.Ln(close)
+}
+
+// Ln adds a line of content, printing each value in the way of fmt.Print
+// without spaces between them, followed by a new line.
+func (f *GeneratedFile) Ln(values ...any) {
+	for _, v := range values {
+		_, err := fmt.Fprint(f, v)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	_, err := f.Write([]byte{'\n'})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// flushIndent writes the pending indentation if at the
+// beginning of a line
+func (f *GeneratedFile) flushIndent() {
+	if f.bol && f.level > 0 {
+		_, _ = f.buf.WriteString(strings.Repeat(f.indent, f.level))
+		f.bol = false
+	}
+}
+
+// writeIndented writes content to the buffer prefixing the
+// indentation to every non-empty line
+func (f *GeneratedFile) writeIndented(b []byte) {
+	for len(b) > 0 {
+		if b[0] != '\n' {
+			f.flushIndent()
+		}
+
+		i := bytes.IndexByte(b, '\n')
+		if i < 0 {
+			_, _ = f.buf.Write(b)
+			f.bol = false
+			return
+		}
+
+		_, _ = f.buf.Write(b[:i+1])
+		f.bol = true
+		b = b[i+1:]
+	}
+}
+
+func (f *GeneratedFile) addFinalNewline() {
+	b := f.buf.Bytes()
+	if !f.noFinalNewline && len(b) > 0 && b[len(b)-1] != '\n' {
+		_ = f.buf.WriteByte('\n')
+	}
+}
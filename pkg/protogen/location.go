@@ -0,0 +1,100 @@
+package protogen
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Location represents a span on a .proto source file. Lines and columns
+// are one-based, and zero when unknown.
+type Location struct {
+	File string
+
+	StartLine, StartColumn int
+	EndLine, EndColumn     int
+}
+
+// IsZero tells if the Location doesn't point anywhere
+func (loc Location) IsZero() bool {
+	return loc.File == "" && loc.StartLine == 0
+}
+
+// String returns the location in the file:line:column format
+func (loc Location) String() string {
+	switch {
+	case loc.StartLine == 0:
+		return loc.File
+	case loc.StartColumn == 0:
+		return fmt.Sprintf("%s:%v", loc.File, loc.StartLine)
+	default:
+		return fmt.Sprintf("%s:%v:%v", loc.File, loc.StartLine, loc.StartColumn)
+	}
+}
+
+// locationAt finds the span of the given path within this file
+func (f *File) locationAt(path protoreflect.SourcePath) Location {
+	loc := Location{
+		File: f.Name(),
+	}
+
+	src := f.SourceLocation(path)
+	if src == nil {
+		return loc
+	}
+
+	// [start line, start column, end line, end column] or
+	// [start line, start column, end column], zero-based
+	switch span := src.Span; len(span) {
+	case 3:
+		loc.StartLine, loc.StartColumn = int(span[0])+1, int(span[1])+1
+		loc.EndLine, loc.EndColumn = int(span[0])+1, int(span[2])+1
+	case 4:
+		loc.StartLine, loc.StartColumn = int(span[0])+1, int(span[1])+1
+		loc.EndLine, loc.EndColumn = int(span[2])+1, int(span[3])+1
+	}
+
+	return loc
+}
+
+// Location returns the name of the file as [Location]
+func (f *File) Location() Location {
+	return Location{
+		File: f.Name(),
+	}
+}
+
+// Location returns where this message was declared
+func (p *Message) Location() Location {
+	return p.File().locationAt(p.SourcePath())
+}
+
+// Location returns where this enum was declared
+func (p *Enum) Location() Location {
+	return p.File().locationAt(p.SourcePath())
+}
+
+// Location returns where this enum value was declared
+func (p *EnumValue) Location() Location {
+	return p.File().locationAt(p.SourcePath())
+}
+
+// Location returns where this field was declared
+func (p *Field) Location() Location {
+	return p.File().locationAt(p.SourcePath())
+}
+
+// Location returns where this oneof was declared
+func (p *Oneof) Location() Location {
+	return p.File().locationAt(p.SourcePath())
+}
+
+// Location returns where this service was declared
+func (p *Service) Location() Location {
+	return p.File().locationAt(p.SourcePath())
+}
+
+// Location returns where this method was declared
+func (p *Method) Location() Location {
+	return p.File().locationAt(p.SourcePath())
+}
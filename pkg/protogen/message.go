@@ -13,12 +13,16 @@ var (
 
 // Message represents a type
 type Message struct {
-	file *File
-	msg  *Message
-	dp   *descriptorpb.DescriptorProto
+	file  *File
+	msg   *Message
+	dp    *descriptorpb.DescriptorProto
+	index int
 
 	enums    []*Enum
+	fields   []*Field
 	messages []*Message
+	oneofs   []*Oneof
+	features *descriptorpb.FeatureSet
 }
 
 // Request returns the [pluginpb.CodeGeneratorRequest] received by
@@ -84,14 +88,15 @@ func (p *Message) Messages() []*Message {
 
 func (p *Message) loadMessages() {
 	out := make([]*Message, 0, len(p.dp.NestedType))
-	for _, dp := range p.dp.NestedType {
+	for i, dp := range p.dp.NestedType {
 		if dp == nil {
 			continue
 		}
 
 		q := &Message{
-			msg: p,
-			dp:  dp,
+			msg:   p,
+			dp:    dp,
+			index: i,
 		}
 
 		out = append(out, q)
@@ -139,14 +144,15 @@ func (f *File) MessageByName(name string) *Message {
 
 func (f *File) loadMessages() {
 	out := make([]*Message, 0, len(f.dp.MessageType))
-	for _, dp := range f.dp.MessageType {
+	for i, dp := range f.dp.MessageType {
 		if dp == nil {
 			continue
 		}
 
 		p := &Message{
-			file: f,
-			dp:   dp,
+			file:  f,
+			dp:    dp,
+			index: i,
 		}
 
 		out = append(out, p)
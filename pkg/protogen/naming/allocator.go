@@ -0,0 +1,48 @@
+package naming
+
+import "strconv"
+
+// Allocator hands out unique identifiers within a scope,
+// escaping reserved words of its [Language]
+type Allocator struct {
+	lang Language
+	used map[string]bool
+}
+
+// NewAllocator creates an [Allocator] for the given [Language]
+func NewAllocator(lang Language) *Allocator {
+	return &Allocator{
+		lang: lang,
+		used: make(map[string]bool),
+	}
+}
+
+// Language returns the [Language] used for escaping
+func (a *Allocator) Language() Language {
+	return a.lang
+}
+
+// Reserve marks names as used, without escaping them
+func (a *Allocator) Reserve(names ...string) {
+	for _, s := range names {
+		a.used[s] = true
+	}
+}
+
+// Has tells if a name has already been used
+func (a *Allocator) Has(name string) bool {
+	return a.used[name]
+}
+
+// Allocate returns a unique identifier based on the given name,
+// escaped if reserved, or followed by a numeric suffix if
+// already used.
+func (a *Allocator) Allocate(name string) string {
+	s := Escape(a.lang, name)
+	for i := 2; a.used[s]; i++ {
+		s = name + "_" + strconv.Itoa(i)
+	}
+
+	a.used[s] = true
+	return s
+}
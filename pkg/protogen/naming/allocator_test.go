@@ -0,0 +1,78 @@
+package naming
+
+import (
+	"reflect"
+	"testing"
+)
+
+type allocatorCase struct {
+	name    string
+	lang    Language
+	reserve []string
+	in      []string
+	want    []string
+}
+
+func (tc allocatorCase) check(t *testing.T) {
+	a := NewAllocator(tc.lang)
+	a.Reserve(tc.reserve...)
+
+	var got []string
+	for _, s := range tc.in {
+		got = append(got, a.Allocate(s))
+	}
+
+	if !reflect.DeepEqual(got, tc.want) {
+		t.Errorf("Allocate(%q) = %q, expected %q", tc.in, got, tc.want)
+	}
+	for _, s := range got {
+		if !a.Has(s) {
+			t.Errorf("Has(%q) = false", s)
+		}
+	}
+}
+
+func TestAllocator(t *testing.T) {
+	tests := []allocatorCase{
+		{
+			name: "unique",
+			lang: Go,
+			in:   []string{"foo", "bar"},
+			want: []string{"foo", "bar"},
+		},
+		{
+			name: "clash",
+			lang: Go,
+			in:   []string{"foo", "foo", "foo"},
+			want: []string{"foo", "foo_2", "foo_3"},
+		},
+		{
+			name: "keyword",
+			lang: Go,
+			in:   []string{"type", "type", "type"},
+			want: []string{"type_", "type_2", "type_3"},
+		},
+		{
+			name: "rust raw keyword",
+			lang: Rust,
+			in:   []string{"type", "type", "self"},
+			want: []string{"r#type", "type_2", "self_"},
+		},
+		{
+			name:    "reserved",
+			lang:    Go,
+			reserve: []string{"foo", "foo_2"},
+			in:      []string{"foo", "bar"},
+			want:    []string{"foo_3", "bar"},
+		},
+		{
+			name: "unknown language",
+			in:   []string{"type", "type"},
+			want: []string{"type", "type_2"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.check)
+	}
+}
@@ -0,0 +1,131 @@
+package naming
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Language identifies a target language for identifier escaping
+type Language string
+
+// Supported languages
+const (
+	Go         Language = "go"
+	C          Language = "c"
+	CPP        Language = "c++"
+	Python     Language = "python"
+	TypeScript Language = "typescript"
+	Rust       Language = "rust"
+	Java       Language = "java"
+)
+
+var keywords = map[Language]map[string]bool{
+	Go: set(`break case chan const continue default defer else fallthrough
+		for func go goto if import interface map package range return select
+		struct switch type var`),
+
+	C: set(`auto break case char const continue default do double else enum
+		extern float for goto if inline int long register restrict return short
+		signed sizeof static struct switch typedef union unsigned void volatile
+		while _Alignas _Alignof _Atomic _Bool _Complex _Generic _Imaginary
+		_Noreturn _Static_assert _Thread_local alignas alignof bool constexpr
+		false nullptr static_assert thread_local true typeof typeof_unqual
+		_BitInt _Decimal128 _Decimal32 _Decimal64`),
+
+	CPP: set(`alignas alignof and and_eq asm auto bitand bitor bool break case
+		catch char char8_t char16_t char32_t class compl concept const consteval
+		constexpr constinit const_cast continue co_await co_return co_yield
+		decltype default delete do double dynamic_cast else enum explicit export
+		extern false float for friend goto if inline int long mutable namespace
+		new noexcept not not_eq nullptr operator or or_eq private protected
+		public register reinterpret_cast requires return short signed sizeof
+		static static_assert static_cast struct switch template this
+		thread_local throw true try typedef typeid typename union unsigned using
+		virtual void volatile wchar_t while xor xor_eq`),
+
+	Python: set(`False None True and as assert async await break class continue
+		def del elif else except finally for from global if import in is lambda
+		nonlocal not or pass raise return try while with yield`),
+
+	TypeScript: set(`break case catch class const continue debugger default
+		delete do else enum export extends false finally for function if import
+		in instanceof new null return super switch this throw true try typeof
+		var void while with implements interface let package private protected
+		public static yield any boolean bigint never number object string symbol
+		undefined unknown`),
+
+	Rust: set(`as break const continue crate else enum extern false fn for if
+		impl in let loop match mod move mut pub ref return self Self static
+		struct super trait true type unsafe use where while async await dyn
+		abstract become box do final macro override priv typeof unsized virtual
+		yield try gen`),
+
+	Java: set(`abstract assert boolean break byte case catch char class const
+		continue default do double else enum extends final finally float for
+		goto if implements import instanceof int interface long native new
+		package private protected public return short static strictfp super
+		switch synchronized this throw throws transient try void volatile while
+		true false null var record yield sealed permits _`),
+}
+
+// Rust keywords that can't be used as raw identifiers
+var rustNonRaw = set(`crate self Self super`)
+
+func set(s string) map[string]bool {
+	out := make(map[string]bool)
+	for _, w := range strings.Fields(s) {
+		out[w] = true
+	}
+	return out
+}
+
+// IsKeyword tells if the name is reserved in the given language
+func IsKeyword(lang Language, name string) bool {
+	return keywords[lang][name]
+}
+
+// Keywords returns the reserved words of the given language
+func Keywords(lang Language) []string {
+	out := make([]string, 0, len(keywords[lang]))
+	for k := range keywords[lang] {
+		out = append(out, k)
+	}
+	return out
+}
+
+// Escape converts a name into a usable identifier if it's
+// reserved in the given language, using r# on Rust when possible
+// or appending an underscore otherwise.
+func Escape(lang Language, name string) string {
+	switch {
+	case !IsKeyword(lang, name):
+		return name
+	case lang == Rust && !rustNonRaw[name]:
+		return "r#" + name
+	default:
+		return name + "_"
+	}
+}
+
+// LanguageByExtension guesses the [Language] of a file by its
+// extension, or returns an empty string if unknown
+func LanguageByExtension(filename string) Language {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".go":
+		return Go
+	case ".c", ".h":
+		return C
+	case ".cc", ".cpp", ".cxx", ".hh", ".hpp", ".hxx":
+		return CPP
+	case ".py", ".pyi":
+		return Python
+	case ".ts", ".tsx", ".mts":
+		return TypeScript
+	case ".rs":
+		return Rust
+	case ".java":
+		return Java
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,191 @@
+// Package naming provides name casing, identifier escaping and
+// collision avoidance for generated code
+package naming
+
+import (
+	"go/token"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// JSONName converts a field name into the lowerCamelCase form
+// protoc uses for json_name when not explicitly given
+func JSONName(s string) string {
+	var upper bool
+
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '_':
+			upper = true
+		case upper && isASCIILower(c):
+			out = append(out, c-'a'+'A')
+			upper = false
+		default:
+			out = append(out, c)
+			upper = false
+		}
+	}
+	return string(out)
+}
+
+// GoName converts a proto name into the CamelCase Go identifier
+// protoc-gen-go would use for it
+//
+//revive:disable-next-line:cognitive-complexity,cyclomatic kept as close as possible to protoc-gen-go's GoCamelCase
+func GoName(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '.' && i+1 < len(s) && isASCIILower(s[i+1]):
+			// skip over '.' in ".{{lowercase}}"
+		case c == '.':
+			b = append(b, '_')
+		case c == '_' && (i == 0 || s[i-1] == '.'):
+			// ensure we start with a capital letter
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(s) && isASCIILower(s[i+1]):
+			// skip over '_' in "_{{lowercase}}"
+		case isASCIIDigit(c):
+			b = append(b, c)
+		default:
+			// start of a word
+			if isASCIILower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+
+			// and the lower case letters that follow
+			for ; i+1 < len(s) && isASCIILower(s[i+1]); i++ {
+				b = append(b, s[i+1])
+			}
+		}
+	}
+	return string(b)
+}
+
+// GoSanitized converts a string into a valid Go identifier the
+// way protoc-gen-go does for package names
+func GoSanitized(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, s)
+
+	r, _ := utf8.DecodeRuneInString(s)
+	if token.Lookup(s).IsKeyword() || !unicode.IsLetter(r) {
+		return "_" + s
+	}
+	return s
+}
+
+// SplitWords breaks an identifier into words on separators,
+// case changes and acronyms. e.g. "HTTPServer_v2" becomes
+// ["HTTP", "Server", "v2"]
+func SplitWords(s string) []string {
+	var words []string
+	var cur []rune
+
+	runes := []rune(s)
+	for i, r := range runes {
+		sep := isWordSeparator(r)
+		if sep || isWordStart(cur, runes[i:]) {
+			words, cur = appendWord(words, cur), cur[:0]
+		}
+		if !sep {
+			cur = append(cur, r)
+		}
+	}
+
+	return appendWord(words, cur)
+}
+
+func isWordSeparator(r rune) bool {
+	return r == '_' || r == '-' || r == '.' || unicode.IsSpace(r)
+}
+
+// isWordStart tells if the first of the remaining runes starts a
+// new word after cur. e.g. fooBar or HTTPServer
+func isWordStart(cur, rest []rune) bool {
+	if len(cur) == 0 || !unicode.IsUpper(rest[0]) {
+		return false
+	}
+
+	prev := cur[len(cur)-1]
+	nextLower := len(rest) > 1 && unicode.IsLower(rest[1])
+	return !unicode.IsUpper(prev) || nextLower
+}
+
+func appendWord(words []string, cur []rune) []string {
+	if len(cur) > 0 {
+		words = append(words, string(cur))
+	}
+	return words
+}
+
+// Capitalize converts the first letter to upper case
+// and the rest to lower case
+func Capitalize(s string) string {
+	for i, r := range s {
+		return string(unicode.ToUpper(r)) + strings.ToLower(s[i+utf8.RuneLen(r):])
+	}
+	return s
+}
+
+// PascalCase converts a name into UpperCamelCase
+func PascalCase(s string) string {
+	var buf strings.Builder
+	for _, w := range SplitWords(s) {
+		_, _ = buf.WriteString(Capitalize(w))
+	}
+	return buf.String()
+}
+
+// CamelCase converts a name into lowerCamelCase
+func CamelCase(s string) string {
+	var buf strings.Builder
+	for i, w := range SplitWords(s) {
+		if i == 0 {
+			_, _ = buf.WriteString(strings.ToLower(w))
+		} else {
+			_, _ = buf.WriteString(Capitalize(w))
+		}
+	}
+	return buf.String()
+}
+
+// SnakeCase converts a name into snake_case
+func SnakeCase(s string) string {
+	return joinWords(s, "_", strings.ToLower)
+}
+
+// ScreamingSnakeCase converts a name into SCREAMING_SNAKE_CASE
+func ScreamingSnakeCase(s string) string {
+	return joinWords(s, "_", strings.ToUpper)
+}
+
+// KebabCase converts a name into kebab-case
+func KebabCase(s string) string {
+	return joinWords(s, "-", strings.ToLower)
+}
+
+func joinWords(s, sep string, fn func(string) string) string {
+	words := SplitWords(s)
+	for i, w := range words {
+		words[i] = fn(w)
+	}
+	return strings.Join(words, sep)
+}
+
+func isASCIILower(c byte) bool {
+	return 'a' <= c && c <= 'z'
+}
+
+func isASCIIDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
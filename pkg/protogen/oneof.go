@@ -0,0 +1,171 @@
+package protogen
+
+import (
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+var (
+	_ ProtoTyper = (*Oneof)(nil)
+)
+
+// Oneof represents a group of fields of a [Message] where
+// only one can be set at a time
+type Oneof struct {
+	msg   *Message
+	dp    *descriptorpb.OneofDescriptorProto
+	index int
+
+	fields   []*Field
+	features *descriptorpb.FeatureSet
+}
+
+// Request returns the [pluginpb.CodeGeneratorRequest] received by
+// the [Plugin]
+func (p *Oneof) Request() *pluginpb.CodeGeneratorRequest {
+	return p.msg.Request()
+}
+
+// Proto returns the underlying protobuf structure
+func (p *Oneof) Proto() *descriptorpb.OneofDescriptorProto {
+	return p.dp
+}
+
+// File returns the [File] that defines this oneof
+func (p *Oneof) File() *File {
+	return p.msg.File()
+}
+
+// Package returns the package name associated to this oneof
+func (p *Oneof) Package() string {
+	return p.msg.Package()
+}
+
+// Parent returns the [Message] this oneof belongs to
+func (p *Oneof) Parent() *Message {
+	return p.msg
+}
+
+// Name returns the relative name of this oneof
+func (p *Oneof) Name() string {
+	return optional(p.dp.Name, "")
+}
+
+// FullName returns the fully qualified name of this oneof
+func (p *Oneof) FullName() string {
+	s0 := p.msg.FullName()
+	s1 := p.Name()
+
+	switch {
+	case s0 != "" && s1 != "":
+		return s0 + "." + s1
+	default:
+		return s1
+	}
+}
+
+// Index returns the position of this oneof within the [Message]
+func (p *Oneof) Index() int {
+	return p.index
+}
+
+// Fields returns the member [Field]s of this oneof
+func (p *Oneof) Fields() []*Field {
+	return p.fields
+}
+
+// IsSynthetic tells if this oneof was generated by protoc to
+// track the presence of a proto3 optional field
+func (p *Oneof) IsSynthetic() bool {
+	return len(p.fields) == 1 && p.fields[0].IsProto3Optional()
+}
+
+// Oneof returns the [Oneof] this field is member of, if any.
+// Synthetic oneofs are included.
+func (p *Field) Oneof() *Oneof {
+	i, ok := optional2(p.dp.OneofIndex, 0)
+	if !ok {
+		return nil
+	}
+
+	for _, o := range p.msg.Oneofs() {
+		if o.index == int(i) {
+			return o
+		}
+	}
+	return nil
+}
+
+// IsProto3Optional tells if the field was declared with the
+// proto3 optional keyword
+func (p *Field) IsProto3Optional() bool {
+	return optional(p.dp.Proto3Optional, false)
+}
+
+// HasPresence tells if the field distinguishes between being unset
+// and being set to its default value
+func (p *Field) HasPresence() bool {
+	switch {
+	case p.IsRepeated():
+		return false
+	case p.dp.OneofIndex != nil:
+		// real oneofs and proto3 optional
+		return true
+	case p.Type() == descriptorpb.FieldDescriptorProto_TYPE_MESSAGE,
+		p.Type() == descriptorpb.FieldDescriptorProto_TYPE_GROUP:
+		return true
+	default:
+		return p.Features().GetFieldPresence() != descriptorpb.FeatureSet_IMPLICIT
+	}
+}
+
+// Oneofs returns all the [Oneof]s of this message, in declaration order
+func (p *Message) Oneofs() []*Oneof {
+	if p.oneofs == nil {
+		p.loadOneofs()
+	}
+	return p.oneofs
+}
+
+// OneofByName finds a [Oneof] by name
+func (p *Message) OneofByName(name string) *Oneof {
+	for _, o := range p.Oneofs() {
+		if name == o.Name() {
+			// match
+			return o
+		}
+	}
+	return nil
+}
+
+func (p *Message) loadOneofs() {
+	// by OneofIndex, nil for invalid declarations
+	byIndex := make([]*Oneof, len(p.dp.OneofDecl))
+
+	out := make([]*Oneof, 0, len(p.dp.OneofDecl))
+	for i, dp := range p.dp.OneofDecl {
+		if dp == nil {
+			continue
+		}
+
+		o := &Oneof{
+			msg:   p,
+			dp:    dp,
+			index: i,
+		}
+
+		byIndex[i] = o
+		out = append(out, o)
+	}
+
+	// members, in field number order
+	for _, f := range p.Fields() {
+		i, ok := optional2(f.dp.OneofIndex, 0)
+		if ok && i >= 0 && int(i) < len(byIndex) && byIndex[i] != nil {
+			o := byIndex[i]
+			o.fields = append(o.fields, f)
+		}
+	}
+
+	p.oneofs = out
+}
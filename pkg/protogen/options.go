@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"google.golang.org/protobuf/types/descriptorpb"
 	"google.golang.org/protobuf/types/pluginpb"
 )
 
@@ -24,7 +25,9 @@ type Options struct {
 	//   --foo_out=<param1>=<value1>,<param2>=<value2>:<output_directory>
 	//
 	// Parameters passed in this fashion as a comma-separated list of
-	// key=value pairs will be passed to the ParamFunc.
+	// key=value pairs will be passed to the ParamFunc, except the
+	// M<file>=<importpath> mappings consumed by [Plugin.ImportMap],
+	// and paths= and module= if OutputPaths is set.
 	//
 	// The (flag.FlagSet).Set method matches this function signature,
 	// so parameters can be converted into flags as in the following:
@@ -39,6 +42,17 @@ type Options struct {
 	//   })
 	ParamFunc func(name, value string) error
 
+	// Params is an optional pointer to a struct to be filled with
+	// the generator parameters, as described by the [ParamsTag]
+	// of its fields. Parameters not found on the struct are passed
+	// to ParamFunc if set, or fail with [ErrUnknownParam] otherwise.
+	Params any
+
+	// OutputPaths enables the paths= and module= parameters used by
+	// [File.OutputPath], in the way of protoc-gen-go. Otherwise
+	// they are treated as any other parameter.
+	OutputPaths bool
+
 	// Stdin is the source of the encoded [pluginpb.CodeGeneratorRequest]
 	Stdin io.Reader
 	// Stdout is where we write the encoded [pluginpb.CodeGeneratorResponse]
@@ -49,10 +63,27 @@ type Options struct {
 	// one will be built using Stderr
 	Logger *log.Logger
 
+	// Indent is the default string used for each level of indentation
+	// of a [GeneratedFile]. If not specified [IndentTab] will be used.
+	Indent string
+
+	// Formatters are applied by file extension, e.g. ".go", to the content
+	// of a [GeneratedFile] before it's added to the response. Annotations
+	// are moved along with the snippets they point to.
+	Formatters map[string]Formatter
+
 	// Features indicates what extra features the plugin supports.
 	// 0: None
 	// 1: Proto3 Optional
+	// 2: Supports Editions
 	Features pluginpb.CodeGeneratorResponse_Feature
+
+	// MinimumEdition and MaximumEdition indicate the range of editions
+	// the plugin supports. Setting MaximumEdition implies
+	// FEATURE_SUPPORTS_EDITIONS, and if the feature is set without
+	// a range only EDITION_2023 is assumed.
+	MinimumEdition descriptorpb.Edition
+	MaximumEdition descriptorpb.Edition
 }
 
 // SetDefaults fills any gap in the Options object
@@ -61,6 +92,10 @@ func (opts *Options) SetDefaults() {
 		opts.Name = filepath.Base(os.Args[0])
 	}
 
+	if opts.Indent == "" {
+		opts.Indent = IndentTab
+	}
+
 	if IsNil(opts.Stdin) {
 		opts.Stdin = os.Stdin
 	}
@@ -73,12 +108,44 @@ func (opts *Options) SetDefaults() {
 		opts.Stderr = os.Stderr
 	}
 
+	opts.setEditionDefaults()
+
 	if IsNil(opts.Logger) {
 		prefix := opts.Name + ": "
 		opts.Logger = log.New(opts.Stderr, prefix, log.Lmsgprefix)
 	}
 }
 
+func (opts *Options) setEditionDefaults() {
+	const editions = pluginpb.CodeGeneratorResponse_FEATURE_SUPPORTS_EDITIONS
+
+	switch {
+	case opts.MaximumEdition != 0:
+		opts.Features |= editions
+	case opts.Features&editions != 0:
+		opts.MaximumEdition = descriptorpb.Edition_EDITION_2023
+	default:
+		return
+	}
+
+	if opts.MinimumEdition == 0 {
+		opts.MinimumEdition = descriptorpb.Edition_EDITION_2023
+	}
+}
+
+// SupportsEdition tells if the plugin can handle files of the
+// given edition. proto2 and proto3 are always supported.
+func (opts *Options) SupportsEdition(edition descriptorpb.Edition) bool {
+	switch {
+	case edition <= descriptorpb.Edition_EDITION_PROTO3:
+		return true
+	case opts.Features&pluginpb.CodeGeneratorResponse_FEATURE_SUPPORTS_EDITIONS == 0:
+		return false
+	default:
+		return edition >= opts.MinimumEdition && edition <= opts.MaximumEdition
+	}
+}
+
 // New allocates a Generator using the Options values
 func (opts *Options) New() (*Plugin, error) {
 	return NewPlugin(opts, nil)
@@ -0,0 +1,166 @@
+package protogen
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// WriteResponseFiles writes the files of a [pluginpb.CodeGeneratorResponse]
+// under the given directory, the way protoc would. Content for insertion
+// points is applied to files of the same response or, if not there,
+// to existing files on the directory.
+func WriteResponseFiles(dir string, resp *pluginpb.CodeGeneratorResponse) error {
+	if s := resp.GetError(); s != "" {
+		return errors.New(s)
+	}
+
+	w := &responseWriter{
+		dir:     dir,
+		content: make(map[string][]byte),
+	}
+
+	for _, g := range resp.File {
+		if err := w.add(g); err != nil {
+			return err
+		}
+	}
+
+	return w.flush()
+}
+
+type responseWriter struct {
+	dir     string
+	names   []string
+	content map[string][]byte
+	last    string
+}
+
+func (w *responseWriter) add(g *pluginpb.CodeGeneratorResponse_File) error {
+	name := g.GetName()
+	content := []byte(g.GetContent())
+
+	switch {
+	case name == "" && w.last == "":
+		return &fs.PathError{Op: "append", Path: name, Err: ErrInvalidName}
+	case name == "":
+		// continuation of the previous file
+		w.content[w.last] = append(w.content[w.last], content...)
+		return nil
+	}
+
+	name, ok := getGeneratedName(name)
+	if !ok {
+		return &fs.PathError{Op: "create", Path: name, Err: ErrInvalidName}
+	}
+
+	if point := g.GetInsertionPoint(); point != "" {
+		return w.insert(name, point, content)
+	}
+
+	w.set(name, content)
+	return nil
+}
+
+func (w *responseWriter) set(name string, content []byte) {
+	if _, ok := w.content[name]; !ok {
+		w.names = append(w.names, name)
+	}
+	w.content[name] = content
+	w.last = name
+}
+
+func (w *responseWriter) insert(name, point string, content []byte) error {
+	target, ok := w.content[name]
+	if !ok {
+		var err error
+
+		target, err = os.ReadFile(w.path(name))
+		if err != nil {
+			return err
+		}
+	}
+
+	out, ok := applyInsertionPoint(target, point, content)
+	if !ok {
+		return &fs.PathError{
+			Op:   "insert",
+			Path: generatedPath(name, point),
+			Err:  fs.ErrNotExist,
+		}
+	}
+
+	w.set(name, out)
+	return nil
+}
+
+func (w *responseWriter) path(name string) string {
+	return filepath.Join(w.dir, filepath.FromSlash(name))
+}
+
+func (w *responseWriter) flush() error {
+	for _, name := range w.names {
+		filename := w.path(name)
+
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(filename, w.content[name], 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyInsertionPoint inserts content before the line containing the
+// @@protoc_insertion_point(point) marker, using the same indentation
+// as the text preceding the marker
+func applyInsertionPoint(target []byte, point string, content []byte) ([]byte, bool) {
+	marker := []byte("@@protoc_insertion_point(" + point + ")")
+
+	i := bytes.Index(target, marker)
+	if i < 0 {
+		return nil, false
+	}
+
+	// beginning of the marker's line, and its indentation
+	bol := bytes.LastIndexByte(target[:i], '\n') + 1
+	indent := leadingSpace(target[bol:i])
+
+	var buf bytes.Buffer
+	_, _ = buf.Write(target[:bol])
+	writeIndented(&buf, indent, content)
+	_, _ = buf.Write(target[bol:])
+
+	return buf.Bytes(), true
+}
+
+// leadingSpace returns the spaces and tabs at the beginning of s
+func leadingSpace(s []byte) []byte {
+	j := bytes.IndexFunc(s, func(r rune) bool {
+		return r != ' ' && r != '\t'
+	})
+	if j < 0 {
+		return s
+	}
+	return s[:j]
+}
+
+// writeIndented writes content prefixing every non-empty line with
+// indent, and ensuring it ends with a new line
+func writeIndented(buf *bytes.Buffer, indent, content []byte) {
+	for _, line := range bytes.SplitAfter(content, []byte("\n")) {
+		if len(line) > 0 && line[0] != '\n' {
+			_, _ = buf.Write(indent)
+		}
+		_, _ = buf.Write(line)
+	}
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		_ = buf.WriteByte('\n')
+	}
+}
@@ -0,0 +1,87 @@
+package protogen
+
+import "testing"
+
+func TestApplyInsertionPoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string
+		point   string
+		content string
+		want    string
+		ok      bool
+	}{
+		{
+			name:    "top level",
+			target:  "a\n// @@protoc_insertion_point(imports)\nb\n",
+			point:   "imports",
+			content: "x\ny\n",
+			want:    "a\nx\ny\n// @@protoc_insertion_point(imports)\nb\n",
+			ok:      true,
+		},
+		{
+			name:    "indented",
+			target:  "{\n\t  // @@protoc_insertion_point(body)\n}\n",
+			point:   "body",
+			content: "x\n\ny",
+			want:    "{\n\t  x\n\n\t  y\n\t  // @@protoc_insertion_point(body)\n}\n",
+			ok:      true,
+		},
+		{
+			name:    "block comment",
+			target:  "  /* @@protoc_insertion_point(body) */\n",
+			point:   "body",
+			content: "x\n",
+			want:    "  x\n  /* @@protoc_insertion_point(body) */\n",
+			ok:      true,
+		},
+		{
+			name:    "first line",
+			target:  "# @@protoc_insertion_point(top)",
+			point:   "top",
+			content: "x\n",
+			want:    "x\n# @@protoc_insertion_point(top)",
+			ok:      true,
+		},
+		{
+			name:    "empty content",
+			target:  "// @@protoc_insertion_point(top)\n",
+			point:   "top",
+			content: "",
+			want:    "// @@protoc_insertion_point(top)\n",
+			ok:      true,
+		},
+		{
+			name:    "first marker",
+			target:  "// @@protoc_insertion_point(p)\n// @@protoc_insertion_point(p)\n",
+			point:   "p",
+			content: "x\n",
+			want:    "x\n// @@protoc_insertion_point(p)\n// @@protoc_insertion_point(p)\n",
+			ok:      true,
+		},
+		{
+			name:    "missing",
+			target:  "// @@protoc_insertion_point(other)\n",
+			point:   "imports",
+			content: "x\n",
+		},
+		{
+			name:    "prefix of another point",
+			target:  "// @@protoc_insertion_point(imports_extra)\n",
+			point:   "imports",
+			content: "x\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := applyInsertionPoint([]byte(tc.target), tc.point, []byte(tc.content))
+			switch {
+			case ok != tc.ok:
+				t.Errorf("applyInsertionPoint() ok = %v, expected %v", ok, tc.ok)
+			case ok && string(got) != tc.want:
+				t.Errorf("applyInsertionPoint() = %q, expected %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package protogen
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/amery/protogen/pkg/protogen/naming"
+)
+
+// ParamsTag is the struct tag used to describe plugin parameters.
+// The first element is the parameter name, the snake_case field name
+// if empty, or "-" to skip the field. It can be followed by
+// enum=value1|value2 to restrict the accepted values.
+//
+//	type Params struct {
+//		Paths   string `protogen:"paths,enum=import|source_relative" help:"output paths mode"`
+//		Verbose bool   `protogen:"verbose" help:"log more details"`
+//		Plugins []string `protogen:"plugins"`
+//	}
+const ParamsTag = "protogen"
+
+// ParamsHelpTag is the struct tag used to describe a plugin parameter
+// on the help text
+const ParamsHelpTag = "help"
+
+var (
+	errInvalidParamsStruct = errors.New("parameters must be a pointer to a struct")
+)
+
+type paramField struct {
+	name  string
+	enum  []string
+	help  string
+	index []int
+}
+
+func (pf *paramField) usage(v reflect.Value) string {
+	switch {
+	case len(pf.enum) > 0:
+		return pf.name + "=" + strings.Join(pf.enum, "|")
+	case v.Kind() == reflect.Bool:
+		return pf.name
+	default:
+		return pf.name + "=" + strings.ToUpper(pf.name)
+	}
+}
+
+func (pf *paramField) set(v reflect.Value, value string) error {
+	if len(pf.enum) > 0 && !containsString(pf.enum, value) {
+		return fmt.Errorf("expected %s", strings.Join(pf.enum, "|"))
+	}
+
+	return setParamValue(v, value)
+}
+
+func containsString(s []string, v string) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// paramsStruct validates the params argument and returns
+// the struct value to fill
+func paramsStruct(params any) (reflect.Value, error) {
+	v := reflect.ValueOf(params)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, errInvalidParamsStruct
+	}
+	return v.Elem(), nil
+}
+
+// parseParamFields extracts the description of the parameters
+// from the struct type
+func parseParamFields(t reflect.Type) ([]paramField, error) {
+	var out []paramField
+
+	for i := 0; i < t.NumField(); i++ {
+		fields, err := parseParamField(t.Field(i))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, fields...)
+	}
+
+	return out, nil
+}
+
+// parseParamField describes the parameters of a struct field,
+// many if it's an embedded struct
+func parseParamField(sf reflect.StructField) ([]paramField, error) {
+	tag, ok := sf.Tag.Lookup(ParamsTag)
+	switch {
+	case !sf.IsExported() && !sf.Anonymous, tag == "-":
+		return nil, nil
+	case !ok && sf.Anonymous && sf.Type.Kind() == reflect.Struct:
+		return parseEmbeddedParamFields(sf)
+	default:
+		pf, err := newParamField(sf, tag)
+		if err != nil {
+			return nil, err
+		}
+		return []paramField{pf}, nil
+	}
+}
+
+func parseEmbeddedParamFields(sf reflect.StructField) ([]paramField, error) {
+	fields, err := parseParamFields(sf.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range fields {
+		pf := &fields[i]
+		pf.index = append(append([]int{}, sf.Index...), pf.index...)
+	}
+	return fields, nil
+}
+
+func newParamField(sf reflect.StructField, tag string) (paramField, error) {
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = naming.SnakeCase(sf.Name)
+	}
+
+	pf := paramField{
+		name:  name,
+		help:  sf.Tag.Get(ParamsHelpTag),
+		index: sf.Index,
+	}
+
+	for _, opt := range strings.Split(opts, ",") {
+		k, v, _ := strings.Cut(opt, "=")
+		switch k {
+		case "":
+			// skip
+		case "enum":
+			pf.enum = strings.Split(v, "|")
+		default:
+			return pf, fmt.Errorf("%s: invalid %s tag option %q", sf.Name, ParamsTag, k)
+		}
+	}
+
+	return pf, nil
+}
+
+func setParamValue(v reflect.Value, s string) error {
+	if u, ok := textUnmarshaler(v); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+		return nil
+	case reflect.Bool:
+		return setParamBool(v, s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return setParamInt(v, s)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return setParamUint(v, s)
+	case reflect.Float32, reflect.Float64:
+		return setParamFloat(v, s)
+	case reflect.Slice:
+		// repeated
+		return appendParamValue(v, s)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+}
+
+func textUnmarshaler(v reflect.Value) (encoding.TextUnmarshaler, bool) {
+	if !v.CanAddr() {
+		return nil, false
+	}
+
+	u, ok := v.Addr().Interface().(encoding.TextUnmarshaler)
+	return u, ok
+}
+
+func setParamBool(v reflect.Value, s string) error {
+	b, err := strconv.ParseBool(s)
+	if err == nil {
+		v.SetBool(b)
+	}
+	return err
+}
+
+func setParamInt(v reflect.Value, s string) error {
+	n, err := strconv.ParseInt(s, 0, v.Type().Bits())
+	if err == nil {
+		v.SetInt(n)
+	}
+	return err
+}
+
+func setParamUint(v reflect.Value, s string) error {
+	n, err := strconv.ParseUint(s, 0, v.Type().Bits())
+	if err == nil {
+		v.SetUint(n)
+	}
+	return err
+}
+
+func setParamFloat(v reflect.Value, s string) error {
+	n, err := strconv.ParseFloat(s, v.Type().Bits())
+	if err == nil {
+		v.SetFloat(n)
+	}
+	return err
+}
+
+func appendParamValue(v reflect.Value, s string) error {
+	e := reflect.New(v.Type().Elem()).Elem()
+	if err := setParamValue(e, s); err != nil {
+		return err
+	}
+	v.Set(reflect.Append(v, e))
+	return nil
+}
+
+// loadParamFields prepares the description of Options.Params
+func (gen *Plugin) loadParamFields() error {
+	if gen.options.Params == nil || gen.paramFields != nil {
+		return nil
+	}
+
+	v, err := paramsStruct(gen.options.Params)
+	if err == nil {
+		gen.paramFields, err = parseParamFields(v.Type())
+	}
+	return Wrap(err, "Options.Params")
+}
+
+// setStructParam fills the field associated to a parameter,
+// and tells if there was one.
+func (gen *Plugin) setStructParam(k, v string) (bool, error) {
+	if gen.options.Params == nil {
+		return false, nil
+	}
+
+	sv, err := paramsStruct(gen.options.Params)
+	if err != nil {
+		return false, Wrap(err, "Options.Params")
+	}
+
+	pf := gen.paramField(k)
+	if pf == nil {
+		return false, nil
+	}
+
+	if err := pf.set(sv.FieldByIndex(pf.index), v); err != nil {
+		return true, Wrap(ErrInvalidParam, "%s=%q (%s)", k, v, err)
+	}
+	return true, nil
+}
+
+// paramField finds the description of a parameter by name
+func (gen *Plugin) paramField(k string) *paramField {
+	for i := range gen.paramFields {
+		if pf := &gen.paramFields[i]; pf.name == k {
+			return pf
+		}
+	}
+	return nil
+}
+
+// ParamsHelp describes the parameters accepted by a parameters struct,
+// one per line, to be used on the help of the plugin's command.
+func ParamsHelp(params any) (string, error) {
+	v, err := paramsStruct(params)
+	if err != nil {
+		return "", err
+	}
+
+	fields, err := parseParamFields(v.Type())
+	if err != nil {
+		return "", err
+	}
+
+	var buf strings.Builder
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	for i := range fields {
+		pf := &fields[i]
+		fv := v.FieldByIndex(pf.index)
+
+		s := pf.help
+		if !fv.IsZero() && fv.Kind() != reflect.Slice {
+			s += fmt.Sprintf(" (default %q)", fmt.Sprint(fv.Interface()))
+		}
+
+		_, _ = fmt.Fprintf(w, "  %s\t%s\n", pf.usage(fv), strings.TrimSpace(s))
+	}
+	_ = w.Flush()
+
+	// no padding after the last column
+	lines := strings.Split(buf.String(), "\n")
+	for i, s := range lines {
+		lines[i] = strings.TrimRight(s, " ")
+	}
+	return strings.Join(lines, "\n"), nil
+}
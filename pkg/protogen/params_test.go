@@ -0,0 +1,183 @@
+package protogen
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+type testParams struct {
+	Paths   string   `protogen:"paths,enum=import|source_relative" help:"output paths mode"`
+	Verbose bool     `help:"log more details"`
+	Plugins []string `protogen:"plugins" help:"plugins to enable"`
+	Level   int      `protogen:"level"`
+	Skipped string   `protogen:"-"`
+
+	testEmbeddedParams
+}
+
+type testEmbeddedParams struct {
+	MaxSize uint `help:"maximum size"`
+}
+
+func newTestPlugin(opts *Options, params string) (*Plugin, error) {
+	return NewPlugin(opts, &pluginpb.CodeGeneratorRequest{
+		Parameter: proto.String(params),
+	})
+}
+
+func TestParamsHelp(t *testing.T) {
+	tests := []struct {
+		name   string
+		params any
+		want   string
+		err    error
+	}{
+		{
+			name:   "zero",
+			params: &testParams{},
+			want: "" +
+				"  paths=import|source_relative  output paths mode\n" +
+				"  verbose                       log more details\n" +
+				"  plugins=PLUGINS               plugins to enable\n" +
+				"  level=LEVEL\n" +
+				"  max_size=MAX_SIZE             maximum size\n",
+		},
+		{
+			name: "defaults",
+			params: &testParams{
+				Paths:   "import",
+				Plugins: []string{"grpc"},
+				testEmbeddedParams: testEmbeddedParams{
+					MaxSize: 64,
+				},
+			},
+			want: "" +
+				"  paths=import|source_relative  output paths mode (default \"import\")\n" +
+				"  verbose                       log more details\n" +
+				"  plugins=PLUGINS               plugins to enable\n" +
+				"  level=LEVEL\n" +
+				"  max_size=MAX_SIZE             maximum size (default \"64\")\n",
+		},
+		{
+			name:   "not a pointer",
+			params: testParams{},
+			err:    errInvalidParamsStruct,
+		},
+		{
+			name:   "not a struct",
+			params: new(string),
+			err:    errInvalidParamsStruct,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParamsHelp(tc.params)
+			switch {
+			case !errors.Is(err, tc.err):
+				t.Fatalf("unexpected error %v, expected %v", err, tc.err)
+			case got != tc.want:
+				t.Errorf("got:\n%s\nexpected:\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSetParam(t *testing.T) {
+	tests := []struct {
+		name   string
+		params string
+		want   testParams
+		err    error
+	}{
+		{
+			name:   "empty",
+			params: "",
+		},
+		{
+			name:   "fields",
+			params: "paths=source_relative,verbose,level=-2,max_size=0x10",
+			want: testParams{
+				Paths:              "source_relative",
+				Verbose:            true,
+				Level:              -2,
+				testEmbeddedParams: testEmbeddedParams{MaxSize: 16},
+			},
+		},
+		{
+			name:   "repeated",
+			params: "plugins=grpc, plugins=twirp,verbose=false",
+			want: testParams{
+				Plugins: []string{"grpc", "twirp"},
+			},
+		},
+		{
+			name:   "unknown",
+			params: "foo=bar",
+			err:    ErrUnknownParam,
+		},
+		{
+			name:   "skipped",
+			params: "skipped=x",
+			err:    ErrUnknownParam,
+		},
+		{
+			name:   "not in enum",
+			params: "paths=relative",
+			err:    ErrInvalidParam,
+		},
+		{
+			name:   "invalid bool",
+			params: "verbose=maybe",
+			err:    ErrInvalidParam,
+		},
+		{
+			name:   "invalid uint",
+			params: "max_size=-1",
+			err:    ErrInvalidParam,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got testParams
+
+			_, err := newTestPlugin(&Options{Params: &got}, tc.params)
+			switch {
+			case !errors.Is(err, tc.err):
+				t.Fatalf("unexpected error %v, expected %v", err, tc.err)
+			case err == nil && !reflect.DeepEqual(got, tc.want):
+				t.Errorf("got %+v, expected %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSetParamFunc(t *testing.T) {
+	var got [][2]string
+
+	opts := &Options{
+		ParamFunc: func(k, v string) error {
+			got = append(got, [2]string{k, v})
+			return nil
+		},
+	}
+
+	gen, err := newTestPlugin(opts, "foo=bar,baz, foo=qux")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][2]string{{"foo", "bar"}, {"baz", "true"}, {"foo", "qux"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParamFunc got %q, expected %q", got, want)
+	}
+
+	if v, ok := gen.Param("foo"); !ok || v != "qux" {
+		t.Errorf("Param(%q) = %q, %v, expected %q", "foo", v, ok, "qux")
+	}
+}
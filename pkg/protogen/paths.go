@@ -0,0 +1,113 @@
+package protogen
+
+import (
+	"path"
+	"strings"
+)
+
+// PathsMode indicates how [File.OutputPath] computes the
+// destination of generated files
+type PathsMode int
+
+const (
+	// ImportPaths places the output on the directory of the Go import
+	// path of the proto file, or its package if there is none.
+	// Selected with paths=import.
+	ImportPaths PathsMode = iota
+	// SourceRelativePaths places the output on the same directory
+	// as the proto file. Selected with paths=source_relative.
+	SourceRelativePaths
+)
+
+func (m PathsMode) String() string {
+	switch m {
+	case ImportPaths:
+		return "import"
+	case SourceRelativePaths:
+		return "source_relative"
+	default:
+		return "invalid"
+	}
+}
+
+// PathsMode returns the output paths strategy chosen with
+// the paths= parameter
+func (gen *Plugin) PathsMode() PathsMode {
+	return gen.pathsMode
+}
+
+// Module returns the prefix removed from the output paths,
+// chosen with the module= parameter
+func (gen *Plugin) Module() string {
+	return gen.module
+}
+
+// isOutputParam tells if a parameter key is one of the
+// enabled output path parameters
+func (gen *Plugin) isOutputParam(k string) bool {
+	return gen.options.OutputPaths && (k == "paths" || k == "module")
+}
+
+// setOutputParam handles the paths= and module= parameters
+func (gen *Plugin) setOutputParam(k, v string) error {
+	switch {
+	case k == "module":
+		gen.module = v
+	case v == ImportPaths.String():
+		gen.pathsMode = ImportPaths
+	case v == SourceRelativePaths.String():
+		gen.pathsMode = SourceRelativePaths
+	default:
+		return Wrap(ErrInvalidParam, "%s=%q", k, v)
+	}
+	return nil
+}
+
+// OutputPath returns the name of a generated file for this proto file,
+// made of its base name followed by the given suffix, e.g. ".pb.go",
+// and placed following the paths= and module= parameters when
+// [Options.OutputPaths] is set.
+func (f *File) OutputPath(suffix string) (string, error) {
+	var name string
+	var err error
+
+	switch f.gen.pathsMode {
+	case SourceRelativePaths:
+		name, err = f.sourceRelativePath(suffix)
+	default:
+		name, err = f.importOutputPath(suffix)
+	}
+
+	if err != nil {
+		return "", err
+	}
+	if _, ok := getGeneratedName(name); !ok {
+		return "", Wrap(ErrInvalidName, name)
+	}
+	return name, nil
+}
+
+func (f *File) sourceRelativePath(suffix string) (string, error) {
+	if m := f.gen.module; m != "" {
+		return "", Wrap(ErrInvalidParam,
+			"module=%q can't be used with paths=%s", m, SourceRelativePaths)
+	}
+	return f.Base() + suffix, nil
+}
+
+func (f *File) importOutputPath(suffix string) (string, error) {
+	dir := f.GoImportPath()
+	if dir == "" {
+		dir = strings.ReplaceAll(f.Package(), ".", "/")
+	}
+
+	name := path.Join(dir, path.Base(f.Base())+suffix)
+	switch m := f.gen.module; {
+	case m == "":
+		return name, nil
+	case strings.HasPrefix(name, m+"/"):
+		return name[len(m)+1:], nil
+	default:
+		return "", Wrap(ErrInvalidName, "%s: not within module %q", name, m)
+	}
+}
@@ -0,0 +1,78 @@
+package protogen
+
+import (
+	"errors"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+type outputPathCase struct {
+	name    string
+	params  string
+	want    string
+	loadErr error
+	err     error
+}
+
+func (tc outputPathCase) check(t *testing.T) {
+	fd := newGoPackageTestFile("foo/bar.proto", "foo", "example.com/m/foo;foo")
+	gen, err := NewPlugin(&Options{OutputPaths: true}, &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{fd.GetName()},
+		Parameter:      proto.String(tc.params),
+		ProtoFile:      []*descriptorpb.FileDescriptorProto{fd},
+	})
+	switch {
+	case !errors.Is(err, tc.loadErr):
+		t.Fatalf("unexpected error %v, expected %v", err, tc.loadErr)
+	case err != nil:
+		return
+	}
+
+	got, err := gen.FileByName(fd.GetName()).OutputPath(".pb.go")
+	switch {
+	case !errors.Is(err, tc.err):
+		t.Errorf("OutputPath() error %v, expected %v", err, tc.err)
+	case got != tc.want:
+		t.Errorf("OutputPath() = %q, expected %q", got, tc.want)
+	}
+}
+
+func TestOutputPath(t *testing.T) {
+	tests := []outputPathCase{
+		{name: "default", want: "example.com/m/foo/bar.pb.go"},
+		{name: "import", params: "paths=import", want: "example.com/m/foo/bar.pb.go"},
+		{name: "source relative", params: "paths=source_relative", want: "foo/bar.pb.go"},
+		{name: "module", params: "module=example.com/m", want: "foo/bar.pb.go"},
+		{name: "outside module", params: "module=example.com/x", err: ErrInvalidName},
+		{name: "module and source relative", params: "paths=source_relative,module=example.com/m",
+			err: ErrInvalidParam},
+		{name: "invalid paths", params: "paths=relative", loadErr: ErrInvalidParam},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.check)
+	}
+}
+
+func TestOutputParamsDisabled(t *testing.T) {
+	var got []Parameter
+	gen, err := newTestPlugin(&Options{
+		ParamFunc: func(k, v string) error {
+			got = append(got, Parameter{Key: k, Value: v})
+			return nil
+		},
+	}, "paths=foo,module=bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 2 || got[0].Key != "paths" || got[1].Key != "module" {
+		t.Errorf("ParamFunc() got %v, expected paths and module", got)
+	}
+	if gen.PathsMode() != ImportPaths || gen.Module() != "" {
+		t.Errorf("PathsMode() = %v, Module() = %q, expected defaults", gen.PathsMode(), gen.Module())
+	}
+}
@@ -1,6 +1,7 @@
 package protogen
 
 import (
+	"google.golang.org/protobuf/reflect/protoregistry"
 	"google.golang.org/protobuf/types/pluginpb"
 )
 
@@ -10,9 +11,19 @@ type Plugin struct {
 	req     *pluginpb.CodeGeneratorRequest
 	resp    pluginpb.CodeGeneratorResponse
 
-	params    map[string]string
-	files     []*File
-	generated map[string]*GeneratedFile
+	params      map[string]string
+	paramList   []Parameter
+	paramFields []paramField
+	importMap   map[string]string
+	importNames map[string]string
+	pathsMode   PathsMode
+	module      string
+	files       []*File
+	symbols     map[string]ProtoTyper
+	generated   map[generatedKey]*GeneratedFile
+
+	registry    *protoregistry.Files
+	registryErr error
 }
 
 func (gen *Plugin) init(req *pluginpb.CodeGeneratorRequest) error {
@@ -20,6 +31,8 @@ func (gen *Plugin) init(req *pluginpb.CodeGeneratorRequest) error {
 
 	// extra features supported by the plugin
 	gen.resp.SupportedFeatures = PointerOrNil(uint64(gen.options.Features))
+	gen.resp.MinimumEdition = PointerOrNil(int32(gen.options.MinimumEdition))
+	gen.resp.MaximumEdition = PointerOrNil(int32(gen.options.MaximumEdition))
 
 	if req == nil {
 		var err error
@@ -58,9 +71,11 @@ func NewPlugin(opts *Options, req *pluginpb.CodeGeneratorRequest) (*Plugin, erro
 	}
 
 	gen := &Plugin{
-		options:   *opts,
-		params:    make(map[string]string),
-		generated: make(map[string]*GeneratedFile),
+		options:     *opts,
+		params:      make(map[string]string),
+		importMap:   make(map[string]string),
+		importNames: make(map[string]string),
+		generated:   make(map[generatedKey]*GeneratedFile),
 	}
 
 	// always return the *Plugin so it can be used to respond
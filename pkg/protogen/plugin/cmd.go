@@ -2,15 +2,21 @@
 package plugin
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	"github.com/amery/protogen/pkg/protogen"
 )
 
 // CmdName returns the arg[0] of this executable
@@ -26,6 +32,11 @@ type Config struct {
 	Short   string // Short is the short description shown on help
 	Version string // Version indicates the version of the generator
 
+	// Params is an optional pointer to the parameters struct
+	// given to [protogen.Options], used to describe the accepted
+	// parameters on help
+	Params any
+
 	// Run represents the main loop of the generator, returning the
 	// exit code
 	Run func(io.ReadCloser, io.WriteCloser) int
@@ -81,9 +92,15 @@ func NewRoot(cfg *Config) (*cobra.Command, error) {
 
 	cfg.SetDefaults()
 
+	long, err := paramsLong(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	cmd := &cobra.Command{
 		Use:     cfg.Name,
 		Short:   cfg.Short,
+		Long:    long,
 		Version: cfg.Version,
 
 		DisableFlagParsing: true,
@@ -99,13 +116,41 @@ func NewRoot(cfg *Config) (*cobra.Command, error) {
 	flags := cmd.Flags() // non-persistent
 	flags.StringP("input", "f", "", "file to use instead of stdin")
 	flags.StringP("output", "o", "", "file to use instead of stdout")
+	flags.StringP("out-dir", "d", "", "write the generated files to this directory instead of the response")
+	setInputFlags(flags)
 
 	return cmd, nil
 }
 
+// paramsLong generates the long description of the command
+// listing the accepted protoc parameters
+func paramsLong(cfg *Config) (string, error) {
+	if cfg.Params == nil {
+		return "", nil
+	}
+
+	help, err := protogen.ParamsHelp(cfg.Params)
+	if err != nil {
+		return "", err
+	}
+
+	var buf strings.Builder
+	if cfg.Short != "" {
+		_, _ = buf.WriteString(cfg.Short + "\n\n")
+	}
+	_, _ = buf.WriteString("Parameters:\n")
+	_, _ = buf.WriteString(help)
+	return buf.String(), nil
+}
+
 func rootRunE(cmd *cobra.Command, runE runCmd) error {
 	flags := cmd.Flags()
 
+	// flag parsing is ours, so is help
+	if help, _ := flags.GetBool("help"); help {
+		return cmd.Help()
+	}
+
 	// stdin
 	in, err := openFileFlag(flags, "input", os.O_RDONLY, 0)
 	switch {
@@ -117,7 +162,25 @@ func rootRunE(cmd *cobra.Command, runE runCmd) error {
 		in = os.Stdin
 	}
 
-	// stdout
+	req, err := prepareInput(flags, in)
+	if err != nil {
+		return err
+	}
+
+	// generated files directly to disk
+	if dir, _ := flags.GetString("out-dir"); dir != "" {
+		if flags.Changed("output") {
+			return errors.New("--output and --out-dir can't be used together")
+		}
+		return runToDir(req, dir, runE)
+	}
+
+	return runToOutput(flags, req, runE)
+}
+
+// runToOutput runs the plugin writing the response to
+// the --output file, or stdout
+func runToOutput(flags *pflag.FlagSet, in io.ReadCloser, runE runCmd) error {
 	out, err := openFileFlag(flags, "output", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
 	switch {
 	case err != nil:
@@ -128,10 +191,46 @@ func rootRunE(cmd *cobra.Command, runE runCmd) error {
 		out = os.Stdout
 	}
 
+	format, err := getFormatFlag(flags, "output-format")
+	switch {
+	case err != nil:
+		return err
+	case format != protogen.AutoFormat && format != protogen.BinaryFormat:
+		return runFormat(in, out, format, runE)
+	}
+
 	// run plugin
 	return runE(in, out)
 }
 
+// runToDir runs the plugin capturing the response, and writes
+// the generated files to the given directory
+func runToDir(in io.ReadCloser, dir string, runE runCmd) error {
+	var out bufferCloser
+
+	err := runE(in, &out)
+	if e, ok := err.(ExitCoder); ok && e.ExitCode() == 0 {
+		// Run() success
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+
+	resp := &pluginpb.CodeGeneratorResponse{}
+	if err := proto.Unmarshal(out.Bytes(), resp); err != nil {
+		return err
+	}
+
+	return protogen.WriteResponseFiles(dir, resp)
+}
+
+type bufferCloser struct {
+	bytes.Buffer
+}
+
+func (*bufferCloser) Close() error { return nil }
+
 func rootPreRunE(cmd *cobra.Command, args []string) error {
 	flags := cmd.LocalFlags()
 
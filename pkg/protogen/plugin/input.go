@@ -0,0 +1,113 @@
+package plugin
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/spf13/pflag"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	"github.com/amery/protogen/pkg/protogen"
+)
+
+func setInputFlags(flags *pflag.FlagSet) {
+	flags.String("input-format", "auto",
+		"encoding of the input: auto, binary, json or text")
+	flags.String("output-format", "binary",
+		"encoding of the response: binary, json or text")
+	flags.Bool("descriptor-set", false,
+		"input is a FileDescriptorSet instead of a CodeGeneratorRequest")
+	flags.StringArray("file-to-generate", nil,
+		"file of the descriptor set to generate, can be repeated (default: those not imported)")
+	flags.String("parameter", "",
+		"generator parameters to use with a descriptor set")
+}
+
+func getFormatFlag(flags *pflag.FlagSet, name string) (protogen.WireFormat, error) {
+	s, err := flags.GetString(name)
+	if err != nil {
+		return protogen.AutoFormat, err
+	}
+
+	format, err := protogen.ParseWireFormat(s)
+	if err != nil {
+		return format, fmt.Errorf("--%s: %w", name, err)
+	}
+	return format, nil
+}
+
+// prepareInput converts the input into a binary encoded
+// CodeGeneratorRequest if needed
+func prepareInput(flags *pflag.FlagSet, in io.Reader) (io.ReadCloser, error) {
+	format, err := getFormatFlag(flags, "input-format")
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := io.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == protogen.AutoFormat {
+		format = protogen.DetectWireFormat(b)
+	}
+
+	var req *pluginpb.CodeGeneratorRequest
+
+	isSet, _ := flags.GetBool("descriptor-set")
+	switch {
+	case isSet:
+		req, err = descriptorSetRequest(flags, b, format)
+	case format == protogen.BinaryFormat:
+		// as-is
+		return io.NopCloser(bytes.NewReader(b)), nil
+	default:
+		req, err = protogen.UnmarshalCodeGeneratorRequestFormat(bytes.NewReader(b), format)
+	}
+
+	if err == nil {
+		b, err = protogen.MarshalFormat(req, protogen.BinaryFormat)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+// descriptorSetRequest builds a CodeGeneratorRequest from an
+// encoded FileDescriptorSet
+func descriptorSetRequest(flags *pflag.FlagSet, b []byte,
+	format protogen.WireFormat) (*pluginpb.CodeGeneratorRequest, error) {
+	fds, err := protogen.UnmarshalFileDescriptorSetFormat(bytes.NewReader(b), format)
+	if err != nil {
+		return nil, err
+	}
+
+	files, _ := flags.GetStringArray("file-to-generate")
+	params, _ := flags.GetString("parameter")
+	return protogen.NewDescriptorSetRequest(fds, files, params), nil
+}
+
+// runFormat runs the plugin converting the binary response
+// to the requested format
+func runFormat(in io.ReadCloser, out io.Writer, format protogen.WireFormat, runE runCmd) error {
+	var buf bufferCloser
+
+	err := runE(in, &buf)
+
+	resp := &pluginpb.CodeGeneratorResponse{}
+	if e := protogen.UnmarshalFormat(buf.Bytes(), resp, protogen.BinaryFormat); e != nil {
+		if err == nil {
+			err = e
+		}
+		return err
+	}
+
+	if _, e := protogen.MarshalCodeGeneratorResponseFormat(resp, out, format); e != nil && err == nil {
+		err = e
+	}
+	return err
+}
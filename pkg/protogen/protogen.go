@@ -2,6 +2,7 @@
 package protogen
 
 import (
+	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/types/pluginpb"
 )
 
@@ -21,6 +22,11 @@ type ProtoTyper interface {
 	Name() string
 	// FullName returns the fully qualified name of this type
 	FullName() string
+	// Location returns where this type was declared
+	Location() Location
+	// SourcePath returns the path of this type on the
+	// SourceCodeInfo of its File
+	SourcePath() protoreflect.SourcePath
 }
 
 // Run handles the protoc plugin protocol using the provided
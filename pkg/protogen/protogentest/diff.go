@@ -0,0 +1,62 @@
+package protogentest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around
+// the differences
+const diffContext = 3
+
+// Diff describes the differences between the expected and the
+// actual content as a single changed block of lines, after
+// skipping their common beginning and ending.
+func Diff(want, got string) string {
+	a := strings.SplitAfter(want, "\n")
+	b := strings.SplitAfter(got, "\n")
+
+	// common prefix
+	var i int
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+
+	// common suffix
+	ea, eb := len(a), len(b)
+	for ea > i && eb > i && a[ea-1] == b[eb-1] {
+		ea--
+		eb--
+	}
+
+	start := i - diffContext
+	if start < 0 {
+		start = 0
+	}
+	end := ea + diffContext
+	if end > len(a) {
+		end = len(a)
+	}
+
+	var buf strings.Builder
+	_, _ = fmt.Fprintf(&buf, "@@ line %d @@\n", start+1)
+	writeDiffLines(&buf, " ", a[start:i])
+	writeDiffLines(&buf, "-", a[i:ea])
+	writeDiffLines(&buf, "+", b[i:eb])
+	writeDiffLines(&buf, " ", a[ea:end])
+	return buf.String()
+}
+
+func writeDiffLines(buf *strings.Builder, prefix string, lines []string) {
+	for _, s := range lines {
+		if s == "" {
+			// after the last newline
+			continue
+		}
+
+		_, _ = buf.WriteString(prefix + s)
+		if !strings.HasSuffix(s, "\n") {
+			_, _ = buf.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package protogentest
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name      string
+		want, got string
+		diff      string
+	}{
+		{
+			name: "changed line",
+			want: "a\nb\nc\n",
+			got:  "a\nx\nc\n",
+			diff: "@@ line 1 @@\n a\n-b\n+x\n c\n",
+		},
+		{
+			name: "context",
+			want: "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			got:  "1\n2\n3\n4\nx\n6\n7\n8\n9\n",
+			diff: "@@ line 2 @@\n 2\n 3\n 4\n-5\n+x\n 6\n 7\n 8\n",
+		},
+		{
+			name: "added",
+			want: "a\n",
+			got:  "a\nb\n",
+			diff: "@@ line 1 @@\n a\n+b\n",
+		},
+		{
+			name: "removed",
+			want: "a\nb\n",
+			got:  "b\n",
+			diff: "@@ line 1 @@\n-a\n b\n",
+		},
+		{
+			name: "no newline at end",
+			want: "a\nb\n",
+			got:  "a\nb",
+			diff: "@@ line 1 @@\n a\n-b\n+b\n\\ No newline at end of file\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Diff(tc.want, tc.got); got != tc.diff {
+				t.Errorf("Diff() = %q, expected %q", got, tc.diff)
+			}
+		})
+	}
+}
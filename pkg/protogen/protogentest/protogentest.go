@@ -0,0 +1,196 @@
+// Package protogentest assists at testing protoc plugins built
+// with protogen against golden files
+package protogentest
+
+import (
+	"bytes"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	"github.com/amery/protogen/pkg/protogen"
+)
+
+// RequestSuffix is the extension of the binary encoded
+// [pluginpb.CodeGeneratorRequest] fixtures, as saved by protoc-gen-dump
+const RequestSuffix = ".req.pb"
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// Update tells if the golden files should be rewritten
+// instead of compared, as requested with -update
+func Update() bool {
+	return *update
+}
+
+// LoadRequest reads a [pluginpb.CodeGeneratorRequest] fixture. Files
+// ending in [RequestSuffix] are read as an encoded request, otherwise
+// as an encoded [descriptorpb.FileDescriptorSet] generating the files
+// not imported by others.
+func LoadRequest(t testing.TB, filename string) *pluginpb.CodeGeneratorRequest {
+	t.Helper()
+
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.HasSuffix(filename, RequestSuffix) {
+		req := &pluginpb.CodeGeneratorRequest{}
+		if err := proto.Unmarshal(b, req); err != nil {
+			t.Fatalf("%s: %s", filename, err)
+		}
+		return req
+	}
+
+	fds, err := protogen.UnmarshalFileDescriptorSet(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("%s: %s", filename, err)
+	}
+
+	return NewRequest(fds, "")
+}
+
+// NewRequest assembles a [pluginpb.CodeGeneratorRequest] from a
+// [descriptorpb.FileDescriptorSet] to generate the given files,
+// or those not imported by others if none is specified.
+// See [protogen.NewDescriptorSetRequest].
+func NewRequest(fds *descriptorpb.FileDescriptorSet, param string,
+	files ...string) *pluginpb.CodeGeneratorRequest {
+	return protogen.NewDescriptorSetRequest(fds, files, param)
+}
+
+// Compile parses .proto sources from a [fs.FS] into a request
+// using [protogen.CompileRequest], failing the test on error
+func Compile(t testing.TB, fsys fs.FS, params string, files ...string) *pluginpb.CodeGeneratorRequest {
+	t.Helper()
+
+	req, err := protogen.CompileRequest(fsys, files, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+// Run executes a [protogen.Handler] over a request, failing the test
+// if the plugin fails
+func Run(t testing.TB, opts *protogen.Options, req *pluginpb.CodeGeneratorRequest,
+	h protogen.Handler) *pluginpb.CodeGeneratorResponse {
+	t.Helper()
+
+	gen, err := protogen.NewPlugin(opts, req)
+	if err == nil {
+		err = h(gen)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := gen.Response()
+	if s := resp.GetError(); s != "" {
+		t.Fatal(s)
+	}
+	return resp
+}
+
+// Golden compares the files of a [pluginpb.CodeGeneratorResponse]
+// against those on a directory, or rewrites them if [Update].
+// Content for insertion points is stored as name@point.
+func Golden(t testing.TB, dir string, resp *pluginpb.CodeGeneratorResponse) {
+	t.Helper()
+
+	seen := make(map[string]bool)
+	for _, g := range resp.File {
+		name := g.GetName()
+		if point := g.GetInsertionPoint(); point != "" {
+			name += "@" + point
+		}
+		seen[name] = true
+
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if Update() {
+			writeGolden(t, filename, g.GetContent())
+		} else {
+			compareGolden(t, name, filename, g.GetContent())
+		}
+	}
+
+	checkStaleGolden(t, dir, seen)
+}
+
+// RunGolden loads a fixture, runs the handler over it, and
+// compares the result against a golden directory
+func RunGolden(t testing.TB, fixture, dir string, opts *protogen.Options, h protogen.Handler) {
+	t.Helper()
+
+	req := LoadRequest(t, fixture)
+	resp := Run(t, opts, req, h)
+	Golden(t, dir, resp)
+}
+
+func writeGolden(t testing.TB, filename, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func compareGolden(t testing.TB, name, filename, content string) {
+	t.Helper()
+
+	want, err := os.ReadFile(filename)
+	switch {
+	case os.IsNotExist(err):
+		t.Errorf("%s: missing golden file (use -update)", name)
+	case err != nil:
+		t.Fatal(err)
+	case !bytes.Equal(want, []byte(content)):
+		t.Errorf("%s: content differs from golden file (use -update)\n%s",
+			name, Diff(string(want), content))
+	}
+}
+
+// checkStaleGolden reports golden files not produced by the
+// plugin, or removes them if [Update]
+func checkStaleGolden(t testing.TB, dir string, seen map[string]bool) {
+	t.Helper()
+
+	err := filepath.WalkDir(dir, func(filename string, d fs.DirEntry, err error) error {
+		t.Helper()
+
+		switch {
+		case err != nil:
+			return err
+		case d.IsDir():
+			return nil
+		}
+
+		name, err := filepath.Rel(dir, filename)
+		switch {
+		case err != nil:
+			return err
+		case seen[filepath.ToSlash(name)]:
+			return nil
+		case Update():
+			return os.Remove(filename)
+		default:
+			t.Errorf("%s: golden file not generated", filepath.ToSlash(name))
+			return nil
+		}
+	})
+
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+}
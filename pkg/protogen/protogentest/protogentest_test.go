@@ -0,0 +1,212 @@
+package protogentest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	"github.com/amery/protogen/pkg/protogen"
+)
+
+// testDescriptorSet returns a set of two files, b.proto importing a.proto
+func testDescriptorSet() *descriptorpb.FileDescriptorSet {
+	newMessage := func(name string) *descriptorpb.DescriptorProto {
+		return &descriptorpb.DescriptorProto{Name: proto.String(name)}
+	}
+
+	return &descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{
+			{
+				Name:        proto.String("a.proto"),
+				Package:     proto.String("test.a"),
+				Syntax:      proto.String("proto3"),
+				MessageType: []*descriptorpb.DescriptorProto{newMessage("Foo")},
+			},
+			{
+				Name:        proto.String("b.proto"),
+				Package:     proto.String("test.b"),
+				Syntax:      proto.String("proto3"),
+				Dependency:  []string{"a.proto"},
+				MessageType: []*descriptorpb.DescriptorProto{newMessage("Bar"), newMessage("Baz")},
+			},
+		},
+	}
+}
+
+// listMessages generates a .txt file listing the messages of each
+// file to generate, and inserts the parameters into the first one
+func listMessages(gen *protogen.Plugin) error {
+	var first string
+
+	for _, f := range gen.Files() {
+		if !f.Generate() {
+			continue
+		}
+
+		name, err := listFileMessages(gen, f)
+		switch {
+		case err != nil:
+			return err
+		case first == "":
+			first = name
+		}
+	}
+
+	if first == "" {
+		return nil
+	}
+	return insertParams(gen, first)
+}
+
+func listFileMessages(gen *protogen.Plugin, f *protogen.File) (string, error) {
+	g, err := gen.NewGeneratedFile("%s.txt", f.Base())
+	if err != nil {
+		return "", err
+	}
+
+	g.F("# %s\n", f.Package())
+	for _, m := range f.Messages() {
+		g.F("%s\n", m.FullName())
+	}
+	if err := g.WriteInsertionPoint(protogen.HashComments, "params"); err != nil {
+		return "", err
+	}
+	return g.Name(), g.Close()
+}
+
+func insertParams(gen *protogen.Plugin, name string) error {
+	g, err := gen.NewInsertionPoint(name, "params")
+	if err != nil {
+		return err
+	}
+
+	for _, p := range gen.ParamList() {
+		g.F("%s=%s\n", p.Key, p.Value)
+	}
+	return g.Close()
+}
+
+// recorder captures the errors reported by the harness
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (*recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+type newRequestCase struct {
+	name  string
+	files []string
+	want  []string
+}
+
+func (tc newRequestCase) check(t *testing.T) {
+	req := NewRequest(testDescriptorSet(), "x=1", tc.files...)
+	if got := req.FileToGenerate; !reflect.DeepEqual(got, tc.want) {
+		t.Errorf("FileToGenerate = %q, expected %q", got, tc.want)
+	}
+	if got := req.GetParameter(); got != "x=1" {
+		t.Errorf("Parameter = %q, expected %q", got, "x=1")
+	}
+}
+
+func TestNewRequest(t *testing.T) {
+	tests := []newRequestCase{
+		{"roots", nil, []string{"b.proto"}},
+		{"explicit", []string{"a.proto", "b.proto"}, []string{"a.proto", "b.proto"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.check)
+	}
+}
+
+func TestLoadRequest(t *testing.T) {
+	dir := t.TempDir()
+	fds := testDescriptorSet()
+	want := NewRequest(fds, "x=1", "a.proto")
+
+	write := func(name string, m proto.Message) string {
+		b, err := proto.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		filename := filepath.Join(dir, name)
+		if err := os.WriteFile(filename, b, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return filename
+	}
+
+	got := LoadRequest(t, write("test"+RequestSuffix, want))
+	if !proto.Equal(got, want) {
+		t.Errorf("LoadRequest(%q) = %v, expected %v", "test"+RequestSuffix, got, want)
+	}
+
+	got = LoadRequest(t, write("test.pb", fds))
+	if want := NewRequest(fds, ""); !proto.Equal(got, want) {
+		t.Errorf("LoadRequest(%q) = %v, expected %v", "test.pb", got, want)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	req := NewRequest(testDescriptorSet(), "x=1,y=2", "a.proto", "b.proto")
+	resp := Run(t, nil, req, listMessages)
+	Golden(t, filepath.Join("testdata", "list"), resp)
+}
+
+func TestGoldenMismatch(t *testing.T) {
+	if Update() {
+		t.Skip("golden files are being rewritten")
+	}
+
+	dir := t.TempDir()
+	req := NewRequest(testDescriptorSet(), "")
+	resp := Run(t, nil, req, listMessages)
+
+	for _, g := range resp.File {
+		name := g.GetName()
+		if point := g.GetInsertionPoint(); point != "" {
+			name += "@" + point
+		}
+		writeGolden(t, filepath.Join(dir, name), g.GetContent())
+	}
+	writeGolden(t, filepath.Join(dir, "stale.txt"), "")
+
+	// changed, missing and stale
+	resp.File[0].Content = proto.String(strings.Replace(resp.File[0].GetContent(),
+		"test.b.Baz", "test.b.Qux", 1))
+	resp.File = append(resp.File, &pluginpb.CodeGeneratorResponse_File{
+		Name:    proto.String("new.txt"),
+		Content: proto.String("new\n"),
+	})
+
+	r := &recorder{TB: t}
+	Golden(r, dir, resp)
+
+	want := []string{
+		"b.txt: content differs from golden file (use -update)\n" +
+			"@@ line 1 @@\n" +
+			" # test.b\n" +
+			" test.b.Bar\n" +
+			"-test.b.Baz\n" +
+			"+test.b.Qux\n" +
+			" # @@protoc_insertion_point(params)\n",
+		"new.txt: missing golden file (use -update)",
+		"stale.txt: golden file not generated",
+	}
+	if !reflect.DeepEqual(r.errors, want) {
+		t.Errorf("got errors %q, expected %q", r.errors, want)
+	}
+}
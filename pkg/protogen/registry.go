@@ -0,0 +1,116 @@
+package protogen
+
+import (
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Registry returns a [protoregistry.Files] built from the source proto
+// files of the request, so they can be used with the protoreflect API.
+func (gen *Plugin) Registry() (*protoregistry.Files, error) {
+	if gen.registry == nil && gen.registryErr == nil {
+		fds := &descriptorpb.FileDescriptorSet{
+			File: gen.req.GetProtoFile(),
+		}
+
+		gen.registry, gen.registryErr = protodesc.NewFiles(fds)
+		if gen.registryErr != nil {
+			gen.registryErr = Wrap(gen.registryErr, "protodesc.NewFiles")
+		}
+	}
+
+	return gen.registry, gen.registryErr
+}
+
+func (gen *Plugin) findDescriptor(name string) protoreflect.Descriptor {
+	reg, err := gen.Registry()
+	if err != nil {
+		return nil
+	}
+
+	d, err := reg.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil
+	}
+	return d
+}
+
+// Descriptor returns the [protoreflect.FileDescriptor] of this file,
+// or nil if the [Plugin.Registry] couldn't be built.
+func (f *File) Descriptor() protoreflect.FileDescriptor {
+	reg, err := f.gen.Registry()
+	if err != nil {
+		return nil
+	}
+
+	fd, err := reg.FindFileByPath(f.Name())
+	if err != nil {
+		return nil
+	}
+	return fd
+}
+
+// Descriptor returns the [protoreflect.MessageDescriptor] of this message,
+// or nil if the [Plugin.Registry] couldn't be built.
+func (p *Message) Descriptor() protoreflect.MessageDescriptor {
+	if d, ok := p.File().gen.findDescriptor(p.FullName()).(protoreflect.MessageDescriptor); ok {
+		return d
+	}
+	return nil
+}
+
+// Descriptor returns the [protoreflect.FieldDescriptor] of this field,
+// or nil if the [Plugin.Registry] couldn't be built.
+func (p *Field) Descriptor() protoreflect.FieldDescriptor {
+	if md := p.msg.Descriptor(); md != nil {
+		return md.Fields().ByNumber(protoreflect.FieldNumber(p.Number()))
+	}
+	return nil
+}
+
+// Descriptor returns the [protoreflect.OneofDescriptor] of this oneof,
+// or nil if the [Plugin.Registry] couldn't be built.
+func (p *Oneof) Descriptor() protoreflect.OneofDescriptor {
+	if md := p.msg.Descriptor(); md != nil {
+		return md.Oneofs().ByName(protoreflect.Name(p.Name()))
+	}
+	return nil
+}
+
+// Descriptor returns the [protoreflect.EnumDescriptor] of this enum,
+// or nil if the [Plugin.Registry] couldn't be built.
+func (p *Enum) Descriptor() protoreflect.EnumDescriptor {
+	if d, ok := p.File().gen.findDescriptor(p.FullName()).(protoreflect.EnumDescriptor); ok {
+		return d
+	}
+	return nil
+}
+
+// Descriptor returns the [protoreflect.EnumValueDescriptor] of this value,
+// or nil if the [Plugin.Registry] couldn't be built.
+func (p *EnumValue) Descriptor() protoreflect.EnumValueDescriptor {
+	if ed := p.enum.Descriptor(); ed != nil {
+		return ed.Values().ByName(protoreflect.Name(p.Name()))
+	}
+	return nil
+}
+
+// Descriptor returns the [protoreflect.ServiceDescriptor] of this service,
+// or nil if the [Plugin.Registry] couldn't be built.
+func (p *Service) Descriptor() protoreflect.ServiceDescriptor {
+	if d, ok := p.file.gen.findDescriptor(p.FullName()).(protoreflect.ServiceDescriptor); ok {
+		return d
+	}
+	return nil
+}
+
+// Descriptor returns the [protoreflect.MethodDescriptor] of this method,
+// or nil if the [Plugin.Registry] couldn't be built.
+func (p *Method) Descriptor() protoreflect.MethodDescriptor {
+	if sd := p.svc.Descriptor(); sd != nil {
+		return sd.Methods().ByName(protoreflect.Name(p.Name()))
+	}
+	return nil
+}
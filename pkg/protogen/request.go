@@ -4,7 +4,6 @@ import (
 	"io"
 	"strings"
 
-	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/pluginpb"
 )
 
@@ -16,13 +15,17 @@ func (gen *Plugin) Request() *pluginpb.CodeGeneratorRequest {
 // UnmarshalCodeGeneratorRequest reads the proto encoded representation of the
 // [pluginpb.CodeGeneratorRequest] from a [io.Reader]
 func UnmarshalCodeGeneratorRequest(r io.Reader) (*pluginpb.CodeGeneratorRequest, error) {
-	in, err := io.ReadAll(r)
-	if err != nil {
-		return nil, err
-	}
+	return UnmarshalCodeGeneratorRequestFormat(r, BinaryFormat)
+}
 
+// UnmarshalCodeGeneratorRequestFormat reads the representation of the
+// [pluginpb.CodeGeneratorRequest] encoded in the given [WireFormat]
+// from a [io.Reader], detecting it if [AutoFormat]
+func UnmarshalCodeGeneratorRequestFormat(r io.Reader,
+	format WireFormat) (*pluginpb.CodeGeneratorRequest, error) {
 	req := &pluginpb.CodeGeneratorRequest{}
-	if err := proto.Unmarshal(in, req); err != nil {
+	err := readSelfDescribing(r, req, format, req.GetProtoFile)
+	if err != nil {
 		return nil, err
 	}
 
@@ -36,6 +39,16 @@ func (gen *Plugin) loadRequest(req *pluginpb.CodeGeneratorRequest) error {
 		return err
 	}
 
+	// editions
+	if err := gen.checkEditions(); err != nil {
+		return err
+	}
+
+	// type references
+	if err := gen.loadSymbols(); err != nil {
+		return err
+	}
+
 	// Parameter
 	if p := req.Parameter; p != nil {
 		err := gen.loadParams(*p)
@@ -49,32 +62,64 @@ func (gen *Plugin) loadRequest(req *pluginpb.CodeGeneratorRequest) error {
 	return nil
 }
 
-// Param returns the value of a parameter if specified
+// Parameter is a key=value generator parameter
+type Parameter struct {
+	Key   string
+	Value string
+}
+
+// Param returns the value of a parameter if specified. If
+// it was specified multiple times the last value is returned.
 func (gen *Plugin) Param(key string) (string, bool) {
 	value, found := gen.params[key]
 	return value, found
 }
 
-// Params returns all specified parameters
+// Params returns all specified parameters, with the last value
+// of those specified multiple times
 func (gen *Plugin) Params() map[string]string {
 	return gen.params
 }
 
-func (gen *Plugin) loadParams(params string) error {
-	for _, s := range strings.Split(params, ",") {
-		s = strings.TrimSpace(s)
-		if s == "" {
-			// skip empty
-			continue
+// ParamValues returns all the values given to a parameter,
+// in order
+func (gen *Plugin) ParamValues(key string) []string {
+	var out []string
+	for _, p := range gen.paramList {
+		if p.Key == key {
+			out = append(out, p.Value)
 		}
+	}
+	return out
+}
 
-		k, v, found := strings.Cut(s, "=")
-		if !found {
-			v = "true"
-		}
+// ParamList returns every parameter occurrence in the order
+// they were specified
+func (gen *Plugin) ParamList() []Parameter {
+	return gen.paramList
+}
 
-		err := gen.setParam(k, v)
-		if err != nil {
+// ImportMap returns the proto file to import path table
+// given via M<file>=<importpath> parameters, without the
+// optional ;<name> suffix
+func (gen *Plugin) ImportMap() map[string]string {
+	return gen.importMap
+}
+
+// ImportPathOf returns the import path given to a proto file
+// via a M<file>=<importpath> parameter
+func (gen *Plugin) ImportPathOf(filename string) (string, bool) {
+	value, found := gen.importMap[filename]
+	return value, found
+}
+
+func (gen *Plugin) loadParams(params string) error {
+	if err := gen.loadParamFields(); err != nil {
+		return err
+	}
+
+	for _, s := range strings.Split(params, ",") {
+		if err := gen.loadParam(strings.TrimSpace(s)); err != nil {
 			return err
 		}
 	}
@@ -82,14 +127,66 @@ func (gen *Plugin) loadParams(params string) error {
 	return nil
 }
 
-func (gen *Plugin) setParam(k, v string) error {
-	var err error
+// loadParam applies a key=value parameter, a key alone
+// meaning "true"
+func (gen *Plugin) loadParam(s string) error {
+	if s == "" {
+		// skip empty
+		return nil
+	}
 
+	k, v, found := strings.Cut(s, "=")
+	switch {
+	case found:
+		// k=v
+	case isImportMapParam(k):
+		return Wrap(ErrInvalidParam, "%s: missing import path", k)
+	default:
+		v = "true"
+	}
+	return gen.setParam(k, v)
+}
+
+func (gen *Plugin) setParam(k, v string) error {
 	gen.params[k] = v
+	gen.paramList = append(gen.paramList, Parameter{Key: k, Value: v})
+
+	found, err := gen.setStructParam(k, v)
+	switch {
+	case err != nil:
+		return err
+	case gen.isOutputParam(k):
+		// also when declared in Options.Params
+		return gen.setOutputParam(k, v)
+	case found:
+		return nil
+	case isImportMapParam(k):
+		return gen.setImportMapParam(k, v)
+	case gen.options.ParamFunc != nil:
+		return gen.options.ParamFunc(k, v)
+	case gen.options.Params != nil:
+		return Wrap(ErrUnknownParam, k)
+	default:
+		return nil
+	}
+}
 
-	if gen.options.ParamFunc != nil {
-		err = gen.options.ParamFunc(k, v)
+// isImportMapParam tells if a parameter key is of the
+// M<file>=<importpath> form
+func isImportMapParam(k string) bool {
+	return strings.HasPrefix(k, "M") && strings.HasSuffix(k, ".proto")
+}
+
+// setImportMapParam stores a M<file>=<importpath>[;<name>] mapping,
+// splitting the package name the way protoc-gen-go does
+func (gen *Plugin) setImportMapParam(k, v string) error {
+	importPath, name, _ := strings.Cut(v, ";")
+	if importPath == "" {
+		return Wrap(ErrInvalidParam, "%s=%q: missing import path", k, v)
 	}
 
-	return err
+	filename := k[1:]
+	gen.importMap[filename] = importPath
+	gen.importNames[filename] = name
+	return nil
 }
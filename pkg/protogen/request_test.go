@@ -0,0 +1,202 @@
+package protogen
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestParamList(t *testing.T) {
+	gen, err := newTestPlugin(nil, "plugins=grpc,paths=import, plugins=twirp,verbose")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantList := []Parameter{
+		{"plugins", "grpc"},
+		{"paths", "import"},
+		{"plugins", "twirp"},
+		{"verbose", "true"},
+	}
+	if got := gen.ParamList(); !reflect.DeepEqual(got, wantList) {
+		t.Errorf("ParamList() = %q, expected %q", got, wantList)
+	}
+
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{"plugins", []string{"grpc", "twirp"}},
+		{"paths", []string{"import"}},
+		{"verbose", []string{"true"}},
+		{"unknown", nil},
+	}
+
+	for _, tc := range tests {
+		if got := gen.ParamValues(tc.key); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("ParamValues(%q) = %q, expected %q", tc.key, got, tc.want)
+		}
+	}
+}
+
+type importMapCase struct {
+	name      string
+	params    string
+	wantPaths map[string]string
+	wantNames map[string]string
+	err       error
+}
+
+func (tc importMapCase) check(t *testing.T) {
+	gen, err := newTestPlugin(nil, tc.params)
+	switch {
+	case !errors.Is(err, tc.err):
+		t.Fatalf("unexpected error %v, expected %v", err, tc.err)
+	case err != nil:
+		return
+	}
+
+	if got := gen.ImportMap(); !reflect.DeepEqual(got, tc.wantPaths) {
+		t.Errorf("ImportMap() = %q, expected %q", got, tc.wantPaths)
+	}
+	if got := gen.importNames; !reflect.DeepEqual(got, tc.wantNames) {
+		t.Errorf("package names = %q, expected %q", got, tc.wantNames)
+	}
+}
+
+func TestImportMapParam(t *testing.T) {
+	tests := []importMapCase{
+		{
+			name:      "path",
+			params:    "Mfoo/bar.proto=example.com/foo/bar",
+			wantPaths: map[string]string{"foo/bar.proto": "example.com/foo/bar"},
+			wantNames: map[string]string{"foo/bar.proto": ""},
+		},
+		{
+			name:      "path and name",
+			params:    "Mfoo/bar.proto=example.com/foo/bar;barpb,Mbaz.proto=example.com/baz",
+			wantPaths: map[string]string{"foo/bar.proto": "example.com/foo/bar", "baz.proto": "example.com/baz"},
+			wantNames: map[string]string{"foo/bar.proto": "barpb", "baz.proto": ""},
+		},
+		{
+			name:      "overridden",
+			params:    "Mfoo.proto=example.com/a;a,Mfoo.proto=example.com/b",
+			wantPaths: map[string]string{"foo.proto": "example.com/b"},
+			wantNames: map[string]string{"foo.proto": ""},
+		},
+		{
+			name:      "not a mapping",
+			params:    "Mode=fast,M=x,Mfoo.txt=y",
+			wantPaths: map[string]string{},
+			wantNames: map[string]string{},
+		},
+		{
+			name:   "no value",
+			params: "Mfoo.proto",
+			err:    ErrInvalidParam,
+		},
+		{
+			name:   "empty value",
+			params: "Mfoo.proto=",
+			err:    ErrInvalidParam,
+		},
+		{
+			name:   "only name",
+			params: "Mfoo.proto=;foo",
+			err:    ErrInvalidParam,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.check)
+	}
+}
+
+func newGoPackageTestFile(name, pkg, goPackage string) *descriptorpb.FileDescriptorProto {
+	fd := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String(name),
+		Package: PointerOrNil(pkg),
+	}
+	if goPackage != "" {
+		fd.Options = &descriptorpb.FileOptions{
+			GoPackage: proto.String(goPackage),
+		}
+	}
+	return fd
+}
+
+type goPackageCase struct {
+	name       string
+	params     string
+	file       *descriptorpb.FileDescriptorProto
+	importPath string
+	pkgName    string
+}
+
+func (tc goPackageCase) check(t *testing.T) {
+	gen, err := NewPlugin(nil, &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{tc.file.GetName()},
+		Parameter:      proto.String(tc.params),
+		ProtoFile:      []*descriptorpb.FileDescriptorProto{tc.file},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := gen.FileByName(tc.file.GetName())
+	if got := f.GoImportPath(); got != tc.importPath {
+		t.Errorf("GoImportPath() = %q, expected %q", got, tc.importPath)
+	}
+	if got := f.GoPackageName(); got != tc.pkgName {
+		t.Errorf("GoPackageName() = %q, expected %q", got, tc.pkgName)
+	}
+}
+
+func TestGoPackage(t *testing.T) {
+	tests := []goPackageCase{
+		{
+			name:       "go_package",
+			file:       newGoPackageTestFile("foo/bar.proto", "foo.bar", "example.com/foo/bar/v2"),
+			importPath: "example.com/foo/bar/v2",
+			pkgName:    "bar",
+		},
+		{
+			name:       "go_package with name",
+			file:       newGoPackageTestFile("foo/bar.proto", "foo.bar", "example.com/foo/bar;barpb"),
+			importPath: "example.com/foo/bar",
+			pkgName:    "barpb",
+		},
+		{
+			name:       "mapped",
+			params:     "Mfoo/bar.proto=example.com/baz",
+			file:       newGoPackageTestFile("foo/bar.proto", "foo.bar", "example.com/foo/bar;barpb"),
+			importPath: "example.com/baz",
+			pkgName:    "baz",
+		},
+		{
+			name:       "mapped with name",
+			params:     "Mfoo/bar.proto=example.com/baz;bazpb",
+			file:       newGoPackageTestFile("foo/bar.proto", "foo.bar", ""),
+			importPath: "example.com/baz",
+			pkgName:    "bazpb",
+		},
+		{
+			name:    "proto package",
+			file:    newGoPackageTestFile("foo/bar.proto", "foo.bar_baz", ""),
+			pkgName: "bar_baz",
+		},
+		{
+			name:    "file name",
+			file:    newGoPackageTestFile("foo/bar-baz.proto", "", ""),
+			pkgName: "bar_baz",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.check)
+	}
+}
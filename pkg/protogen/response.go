@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"io"
 
-	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/pluginpb"
 )
 
@@ -17,8 +16,15 @@ func (gen *Plugin) Response() *pluginpb.CodeGeneratorResponse {
 // given [pluginpb.CodeGeneratorResponse]
 func MarshalCodeGeneratorResponse(resp *pluginpb.CodeGeneratorResponse,
 	w io.Writer) (int64, error) {
+	return MarshalCodeGeneratorResponseFormat(resp, w, BinaryFormat)
+}
+
+// MarshalCodeGeneratorResponseFormat writes the representation of the
+// given [pluginpb.CodeGeneratorResponse] encoded in the given [WireFormat]
+func MarshalCodeGeneratorResponseFormat(resp *pluginpb.CodeGeneratorResponse,
+	w io.Writer, format WireFormat) (int64, error) {
 	// encode
-	b, err := proto.Marshal(resp)
+	b, err := MarshalFormat(resp, format)
 	if err != nil {
 		return 0, err
 	}
@@ -0,0 +1,277 @@
+package protogen
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+var (
+	_ ProtoTyper = (*Service)(nil)
+	_ ProtoTyper = (*Method)(nil)
+)
+
+// Service represents a RPC service
+type Service struct {
+	file  *File
+	dp    *descriptorpb.ServiceDescriptorProto
+	index int
+
+	methods []*Method
+}
+
+// Request returns the [pluginpb.CodeGeneratorRequest] received by
+// the [Plugin]
+func (p *Service) Request() *pluginpb.CodeGeneratorRequest {
+	return p.file.Request()
+}
+
+// Proto returns the underlying protobuf structure
+func (p *Service) Proto() *descriptorpb.ServiceDescriptorProto {
+	return p.dp
+}
+
+// File returns the [File] that defines this service
+func (p *Service) File() *File {
+	return p.file
+}
+
+// Package returns the package name associated to this service
+func (p *Service) Package() string {
+	return p.file.Package()
+}
+
+// Name returns the relative name of this service
+func (p *Service) Name() string {
+	return optional(p.dp.Name, "")
+}
+
+// FullName returns the fully qualified name of this service
+func (p *Service) FullName() string {
+	s0 := p.file.Package()
+	s1 := p.Name()
+
+	switch {
+	case s0 != "" && s1 != "":
+		return s0 + "." + s1
+	default:
+		return s1
+	}
+}
+
+// Methods returns all the [Method]s of this service, in
+// declaration order
+func (p *Service) Methods() []*Method {
+	return p.methods
+}
+
+// MethodByName finds a [Method] by name
+func (p *Service) MethodByName(name string) *Method {
+	for _, m := range p.methods {
+		if name == m.Name() {
+			// match
+			return m
+		}
+	}
+	return nil
+}
+
+func (p *Service) init() {
+	p.methods = make([]*Method, 0, len(p.dp.Method))
+	for i, dp := range p.dp.Method {
+		if dp == nil {
+			continue
+		}
+
+		m := &Method{
+			svc:   p,
+			dp:    dp,
+			index: i,
+		}
+
+		p.methods = append(p.methods, m)
+	}
+}
+
+// StreamingMode describes how a [Method] streams its messages
+type StreamingMode int
+
+const (
+	// Unary methods take a single input and return a single output
+	Unary StreamingMode = iota
+	// ClientStreaming methods take a stream of inputs and return
+	// a single output
+	ClientStreaming
+	// ServerStreaming methods take a single input and return a stream
+	// of outputs
+	ServerStreaming
+	// BidiStreaming methods take a stream of inputs and return
+	// a stream of outputs
+	BidiStreaming
+)
+
+func (m StreamingMode) String() string {
+	switch m {
+	case Unary:
+		return "unary"
+	case ClientStreaming:
+		return "client-streaming"
+	case ServerStreaming:
+		return "server-streaming"
+	case BidiStreaming:
+		return "bidi-streaming"
+	default:
+		return fmt.Sprintf("StreamingMode(%d)", int(m))
+	}
+}
+
+// Method represents a RPC method of a [Service]
+type Method struct {
+	svc   *Service
+	dp    *descriptorpb.MethodDescriptorProto
+	index int
+
+	input  *Message
+	output *Message
+}
+
+// Request returns the [pluginpb.CodeGeneratorRequest] received by
+// the [Plugin]
+func (p *Method) Request() *pluginpb.CodeGeneratorRequest {
+	return p.svc.Request()
+}
+
+// Proto returns the underlying protobuf structure. Method options,
+// like google.api.http, can be accessed through it.
+func (p *Method) Proto() *descriptorpb.MethodDescriptorProto {
+	return p.dp
+}
+
+// File returns the [File] that defines this method
+func (p *Method) File() *File {
+	return p.svc.File()
+}
+
+// Package returns the package name associated to this method
+func (p *Method) Package() string {
+	return p.svc.Package()
+}
+
+// Service returns the [Service] this method belongs to
+func (p *Method) Service() *Service {
+	return p.svc
+}
+
+// Name returns the relative name of this method
+func (p *Method) Name() string {
+	return optional(p.dp.Name, "")
+}
+
+// FullName returns the fully qualified name of this method
+func (p *Method) FullName() string {
+	s0 := p.svc.FullName()
+	s1 := p.Name()
+
+	switch {
+	case s0 != "" && s1 != "":
+		return s0 + "." + s1
+	default:
+		return s1
+	}
+}
+
+// Input returns the [Message] type this method takes
+func (p *Method) Input() *Message {
+	return p.input
+}
+
+// Output returns the [Message] type this method returns
+func (p *Method) Output() *Message {
+	return p.output
+}
+
+// IsClientStreaming tells if the method takes a stream of inputs
+func (p *Method) IsClientStreaming() bool {
+	return optional(p.dp.ClientStreaming, false)
+}
+
+// IsServerStreaming tells if the method returns a stream of outputs
+func (p *Method) IsServerStreaming() bool {
+	return optional(p.dp.ServerStreaming, false)
+}
+
+// IsBidiStreaming tells if the method streams in both directions
+func (p *Method) IsBidiStreaming() bool {
+	return p.IsClientStreaming() && p.IsServerStreaming()
+}
+
+// StreamingMode returns how the method streams its messages
+func (p *Method) StreamingMode() StreamingMode {
+	client, server := p.IsClientStreaming(), p.IsServerStreaming()
+	switch {
+	case client && server:
+		return BidiStreaming
+	case client:
+		return ClientStreaming
+	case server:
+		return ServerStreaming
+	default:
+		return Unary
+	}
+}
+
+// Services returns all the [Service]s defined on this file
+func (f *File) Services() []*Service {
+	if f.services == nil {
+		f.loadServices()
+	}
+	return f.services
+}
+
+// ServiceByName finds a [Service] by name
+func (f *File) ServiceByName(name string) *Service {
+	pkgName, name, _ := SplitName(name)
+
+	switch {
+	case pkgName != "" && f.Package() != pkgName:
+		// wrong package
+		return nil
+	case name == "":
+		// no name
+		return nil
+	default:
+		for _, p := range f.Services() {
+			if name == p.Name() {
+				// match
+				return p
+			}
+		}
+		return nil
+	}
+}
+
+func (f *File) loadServices() {
+	out := make([]*Service, 0, len(f.dp.Service))
+	for i, dp := range f.dp.Service {
+		if dp == nil {
+			continue
+		}
+
+		p := &Service{
+			file:  f,
+			dp:    dp,
+			index: i,
+		}
+
+		p.init()
+		out = append(out, p)
+	}
+
+	// sort services by name
+	Sort(out, func(a, b *Service) bool {
+		return a.Name() < b.Name()
+	})
+
+	f.services = out
+}
@@ -0,0 +1,119 @@
+package protogen
+
+import (
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// field numbers used to build [protoreflect.SourcePath]s
+const (
+	filePackageTag     = 2
+	fileMessageTypeTag = 4
+	fileEnumTypeTag    = 5
+	fileServiceTag     = 6
+	fileSyntaxTag      = 12
+
+	messageFieldTag      = 2
+	messageNestedTypeTag = 3
+	messageEnumTypeTag   = 4
+	messageOneofDeclTag  = 8
+
+	enumValueTag     = 2
+	serviceMethodTag = 2
+)
+
+func appendPath(parent protoreflect.SourcePath, tag int32, index int) protoreflect.SourcePath {
+	out := make(protoreflect.SourcePath, 0, len(parent)+2)
+	out = append(out, parent...)
+	return append(out, tag, int32(index))
+}
+
+func pathKey(path protoreflect.SourcePath) string {
+	var buf strings.Builder
+	for i, v := range path {
+		if i > 0 {
+			_ = buf.WriteByte(',')
+		}
+		_, _ = buf.WriteString(strconv.Itoa(int(v)))
+	}
+	return buf.String()
+}
+
+func (f *File) loadLocations() {
+	f.locations = make(map[string]*descriptorpb.SourceCodeInfo_Location)
+
+	if f.dp.SourceCodeInfo == nil {
+		return
+	}
+
+	for _, loc := range f.dp.SourceCodeInfo.Location {
+		key := pathKey(loc.Path)
+		if _, ok := f.locations[key]; !ok {
+			// first one wins
+			f.locations[key] = loc
+		}
+	}
+}
+
+// SourceLocation returns the raw [descriptorpb.SourceCodeInfo_Location]
+// for the given path within this file, if known
+func (f *File) SourceLocation(path protoreflect.SourcePath) *descriptorpb.SourceCodeInfo_Location {
+	return f.locations[pathKey(path)]
+}
+
+// SourcePath returns the path of this file on its own
+// [descriptorpb.SourceCodeInfo], which is always empty.
+func (*File) SourcePath() protoreflect.SourcePath {
+	return protoreflect.SourcePath{}
+}
+
+// SourcePath returns the path of this message on the
+// [descriptorpb.SourceCodeInfo] of its [File]
+func (p *Message) SourcePath() protoreflect.SourcePath {
+	if p.msg != nil {
+		return appendPath(p.msg.SourcePath(), messageNestedTypeTag, p.index)
+	}
+	return appendPath(nil, fileMessageTypeTag, p.index)
+}
+
+// SourcePath returns the path of this enum on the
+// [descriptorpb.SourceCodeInfo] of its [File]
+func (p *Enum) SourcePath() protoreflect.SourcePath {
+	if p.msg != nil {
+		return appendPath(p.msg.SourcePath(), messageEnumTypeTag, p.index)
+	}
+	return appendPath(nil, fileEnumTypeTag, p.index)
+}
+
+// SourcePath returns the path of this value on the
+// [descriptorpb.SourceCodeInfo] of its [File]
+func (p *EnumValue) SourcePath() protoreflect.SourcePath {
+	return appendPath(p.enum.SourcePath(), enumValueTag, p.index)
+}
+
+// SourcePath returns the path of this field on the
+// [descriptorpb.SourceCodeInfo] of its [File]
+func (p *Field) SourcePath() protoreflect.SourcePath {
+	return appendPath(p.msg.SourcePath(), messageFieldTag, p.index)
+}
+
+// SourcePath returns the path of this oneof on the
+// [descriptorpb.SourceCodeInfo] of its [File]
+func (p *Oneof) SourcePath() protoreflect.SourcePath {
+	return appendPath(p.msg.SourcePath(), messageOneofDeclTag, p.index)
+}
+
+// SourcePath returns the path of this service on the
+// [descriptorpb.SourceCodeInfo] of its [File]
+func (p *Service) SourcePath() protoreflect.SourcePath {
+	return appendPath(nil, fileServiceTag, p.index)
+}
+
+// SourcePath returns the path of this method on the
+// [descriptorpb.SourceCodeInfo] of its [File]
+func (p *Method) SourcePath() protoreflect.SourcePath {
+	return appendPath(p.svc.SourcePath(), serviceMethodTag, p.index)
+}
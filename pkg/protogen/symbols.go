@@ -0,0 +1,201 @@
+package protogen
+
+import (
+	"strings"
+)
+
+// MessageByFullName finds a [Message] by its fully qualified name,
+// with or without leading dot
+func (gen *Plugin) MessageByFullName(name string) *Message {
+	if p, ok := gen.symbols[strings.TrimPrefix(name, ".")].(*Message); ok {
+		return p
+	}
+	return nil
+}
+
+// EnumByFullName finds a [Enum] by its fully qualified name,
+// with or without leading dot
+func (gen *Plugin) EnumByFullName(name string) *Enum {
+	if p, ok := gen.symbols[strings.TrimPrefix(name, ".")].(*Enum); ok {
+		return p
+	}
+	return nil
+}
+
+// Message returns the [Message] type of this field, if any
+func (p *Field) Message() *Message {
+	if m, ok := p.resolved.(*Message); ok {
+		return m
+	}
+	return nil
+}
+
+// Enum returns the [Enum] type of this field, if any
+func (p *Field) Enum() *Enum {
+	if e, ok := p.resolved.(*Enum); ok {
+		return e
+	}
+	return nil
+}
+
+// PublicDependencies returns the source proto files this one
+// imports publicly
+func (f *File) PublicDependencies() []*File {
+	out := make([]*File, 0, len(f.dp.PublicDependency))
+	for _, i := range f.dp.PublicDependency {
+		if i >= 0 && int(i) < len(f.dp.Dependency) {
+			out = append(out, f.gen.getFileByName(f.dp.Dependency[i]))
+		}
+	}
+	return out
+}
+
+// visibleFiles returns the set of files whose symbols
+// are accessible from this file
+func (f *File) visibleFiles() map[*File]bool {
+	seen := map[*File]bool{f: true}
+
+	var addPublic func(*File)
+	addPublic = func(dep *File) {
+		if dep == nil || seen[dep] {
+			return
+		}
+		seen[dep] = true
+		for _, pub := range dep.PublicDependencies() {
+			addPublic(pub)
+		}
+	}
+
+	for _, dep := range f.Dependencies() {
+		addPublic(dep)
+	}
+	return seen
+}
+
+func (gen *Plugin) loadSymbols() error {
+	gen.symbols = make(map[string]ProtoTyper)
+
+	for _, f := range gen.files {
+		gen.addFileSymbols(f)
+	}
+
+	var errs ErrAggregation
+	for _, f := range gen.files {
+		gen.resolveFileSymbols(&errs, f)
+	}
+
+	return errs.AsError()
+}
+
+func (gen *Plugin) addSymbol(p ProtoTyper) {
+	gen.symbols[p.FullName()] = p
+}
+
+func (gen *Plugin) addFileSymbols(f *File) {
+	for _, e := range f.Enums() {
+		gen.addSymbol(e)
+	}
+	for _, m := range f.Messages() {
+		gen.addMessageSymbols(m)
+	}
+	for _, svc := range f.Services() {
+		gen.addSymbol(svc)
+	}
+}
+
+func (gen *Plugin) addMessageSymbols(m *Message) {
+	gen.addSymbol(m)
+
+	for _, e := range m.Enums() {
+		gen.addSymbol(e)
+	}
+	for _, q := range m.Messages() {
+		gen.addMessageSymbols(q)
+	}
+}
+
+func (gen *Plugin) resolveFileSymbols(errs *ErrAggregation, f *File) {
+	visible := f.visibleFiles()
+	for _, m := range f.Messages() {
+		gen.resolveMessageFields(errs, visible, m)
+	}
+	for _, svc := range f.Services() {
+		for _, m := range svc.Methods() {
+			errs.Append(gen.resolveMethod(visible, m))
+		}
+	}
+}
+
+func (gen *Plugin) resolveMessageFields(errs *ErrAggregation, visible map[*File]bool, m *Message) {
+	for _, p := range m.Fields() {
+		errs.Append(gen.resolveField(visible, p))
+	}
+	for _, q := range m.Messages() {
+		gen.resolveMessageFields(errs, visible, q)
+	}
+}
+
+func (gen *Plugin) resolveField(visible map[*File]bool, p *Field) error {
+	name := p.TypeName()
+	if name == "" {
+		// scalar
+		return nil
+	}
+
+	sym := gen.lookupSymbol(p.msg.FullName(), name)
+	switch {
+	case sym == nil:
+		return NewPluginError(p.Location(), ErrUnresolvedType,
+			"%s: type %q not found", p.FullName(), name)
+	case !visible[sym.File()]:
+		return NewPluginError(p.Location(), ErrUnresolvedType,
+			"%s: type %q defined in %q not imported",
+			p.FullName(), name, sym.File().Name())
+	default:
+		p.resolved = sym
+		return nil
+	}
+}
+
+func (gen *Plugin) resolveMethod(visible map[*File]bool, p *Method) error {
+	var err error
+
+	p.input, err = gen.resolveMessage(visible, p, optional(p.dp.InputType, ""))
+	if err == nil {
+		p.output, err = gen.resolveMessage(visible, p, optional(p.dp.OutputType, ""))
+	}
+	return err
+}
+
+func (gen *Plugin) resolveMessage(visible map[*File]bool, p ProtoTyper, name string) (*Message, error) {
+	m, ok := gen.lookupSymbol(p.Package(), name).(*Message)
+	switch {
+	case !ok:
+		return nil, NewPluginError(p.Location(), ErrUnresolvedType,
+			"%s: message %q not found", p.FullName(), name)
+	case !visible[m.File()]:
+		return nil, NewPluginError(p.Location(), ErrUnresolvedType,
+			"%s: message %q defined in %q not imported",
+			p.FullName(), name, m.File().Name())
+	default:
+		return m, nil
+	}
+}
+
+// lookupSymbol finds a type by name. Fully qualified names start with
+// a dot, otherwise they are searched from the innermost scope outward.
+func (gen *Plugin) lookupSymbol(scope, name string) ProtoTyper {
+	if strings.HasPrefix(name, ".") {
+		return gen.symbols[name[1:]]
+	}
+
+	for scope != "" {
+		if sym, ok := gen.symbols[scope+"."+name]; ok {
+			return sym
+		}
+
+		scope, _, _ = SplitName(scope)
+	}
+
+	return gen.symbols[name]
+}
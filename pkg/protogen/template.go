@@ -0,0 +1,207 @@
+package protogen
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+	"text/template"
+
+	"github.com/amery/protogen/pkg/protogen/naming"
+)
+
+// TemplateFuncs returns the functions available to templates created
+// by [NewTemplate] and [LoadTemplates].
+//
+// Case conversions: camel, pascal, snake, screaming, kebab, and
+// protoc's goName and jsonName.
+//
+// Comments: comment STYLE X renders a string or [Comments] in
+// the "//", "#" or "/**" style.
+//
+// Names: fullName, protoName (with leading dot), baseName and
+// packageName of a name or [ProtoTyper].
+//
+// Lookups: findMessage, findEnum and findFile take a [ProtoTyper] or
+// [File] as context and a name to resolve from its scope.
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		// case
+		"camel":     naming.CamelCase,
+		"pascal":    naming.PascalCase,
+		"snake":     naming.SnakeCase,
+		"screaming": naming.ScreamingSnakeCase,
+		"kebab":     naming.KebabCase,
+		"goName":    naming.GoName,
+		"jsonName":  naming.JSONName,
+
+		// comments
+		"comment": templateComment,
+
+		// names
+		"fullName":    templateFullName,
+		"protoName":   templateProtoName,
+		"baseName":    templateBaseName,
+		"packageName": templatePackageName,
+
+		// lookups
+		"findMessage": templateFindMessage,
+		"findEnum":    templateFindEnum,
+		"findFile":    templateFindFile,
+	}
+}
+
+// NewTemplate allocates a new [template.Template] including
+// the [TemplateFuncs]
+func NewTemplate(name string) *template.Template {
+	return template.New(name).Funcs(TemplateFuncs())
+}
+
+// LoadTemplates parses the templates matching the given patterns
+// on a [fs.FS], e.g. one embedded with go:embed, into a set including
+// the [TemplateFuncs]. If no patterns are given "*.tmpl" is used.
+// Templates are named after their base file name.
+func LoadTemplates(fsys fs.FS, patterns ...string) (*template.Template, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"*.tmpl"}
+	}
+
+	t, err := NewTemplate("").ParseFS(fsys, patterns...)
+	if err != nil {
+		return nil, Wrap(err, "LoadTemplates")
+	}
+	return t, nil
+}
+
+// ExecuteTemplate executes the named template of a set over the buffer
+func (f *GeneratedFile) ExecuteTemplate(t *template.Template, name string, data any) error {
+	return t.ExecuteTemplate(f, name, data)
+}
+
+func parseCommentStyle(s string) (CommentStyle, error) {
+	switch s {
+	case "//":
+		return SlashComments, nil
+	case "#":
+		return HashComments, nil
+	case "/**", "/*":
+		return BlockComments, nil
+	default:
+		return 0, fmt.Errorf("invalid comment style %q", s)
+	}
+}
+
+func templateComment(style string, v any) (string, error) {
+	cs, err := parseCommentStyle(style)
+	if err != nil {
+		return "", err
+	}
+
+	switch c := v.(type) {
+	case string:
+		return cs.Format(c), nil
+	case Comments:
+		return cs.FormatComments(c), nil
+	default:
+		return "", fmt.Errorf("comment: invalid argument %T", v)
+	}
+}
+
+func templateName(v any) (string, error) {
+	switch p := v.(type) {
+	case string:
+		return strings.TrimPrefix(p, "."), nil
+	case *File:
+		return p.Package(), nil
+	case ProtoTyper:
+		return p.FullName(), nil
+	default:
+		return "", fmt.Errorf("invalid name argument %T", v)
+	}
+}
+
+func templateFullName(v any) (string, error) {
+	return templateName(v)
+}
+
+func templateProtoName(v any) (string, error) {
+	s, err := templateName(v)
+	if err != nil {
+		return "", err
+	}
+	return "." + s, nil
+}
+
+func templateBaseName(v any) (string, error) {
+	s, err := templateName(v)
+	if err != nil {
+		return "", err
+	}
+
+	_, s, _ = SplitName(s)
+	return s, nil
+}
+
+func templatePackageName(v any) (string, error) {
+	switch p := v.(type) {
+	case *File:
+		return p.Package(), nil
+	case ProtoTyper:
+		return p.Package(), nil
+	default:
+		s, err := templateName(v)
+		if err != nil {
+			return "", err
+		}
+		s, _, _ = SplitName(s)
+		return s, nil
+	}
+}
+
+// templateScope returns the [Plugin] and scope to resolve
+// names relative to a context value
+func templateScope(ctx any) (*Plugin, string, error) {
+	switch p := ctx.(type) {
+	case *File:
+		return p.gen, p.Package(), nil
+	case ProtoTyper:
+		return p.File().gen, p.FullName(), nil
+	default:
+		return nil, "", fmt.Errorf("invalid lookup context %T", ctx)
+	}
+}
+
+func templateFindMessage(ctx any, name string) (*Message, error) {
+	gen, scope, err := templateScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if m, ok := gen.lookupSymbol(scope, name).(*Message); ok {
+		return m, nil
+	}
+	return nil, fmt.Errorf("message %q not found", name)
+}
+
+func templateFindEnum(ctx any, name string) (*Enum, error) {
+	gen, scope, err := templateScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if e, ok := gen.lookupSymbol(scope, name).(*Enum); ok {
+		return e, nil
+	}
+	return nil, fmt.Errorf("enum %q not found", name)
+}
+
+func templateFindFile(ctx any, name string) (*File, error) {
+	gen, _, err := templateScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if f := gen.FileByName(name); f != nil {
+		return f, nil
+	}
+	return nil, fmt.Errorf("file %q not found", name)
+}
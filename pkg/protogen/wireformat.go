@@ -0,0 +1,229 @@
+package protogen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"unicode"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// WireFormat is the encoding of a serialized request, response
+// or descriptor set
+type WireFormat int
+
+const (
+	// AutoFormat detects the encoding from the content when reading,
+	// and uses BinaryFormat when writing
+	AutoFormat WireFormat = iota
+	// BinaryFormat is the protobuf wire encoding used by protoc
+	BinaryFormat
+	// JSONFormat is the protobuf JSON mapping
+	JSONFormat
+	// TextFormat is the protobuf text format
+	TextFormat
+)
+
+func (f WireFormat) String() string {
+	switch f {
+	case AutoFormat:
+		return "auto"
+	case BinaryFormat:
+		return "binary"
+	case JSONFormat:
+		return "json"
+	case TextFormat:
+		return "text"
+	default:
+		return fmt.Sprintf("WireFormat(%d)", int(f))
+	}
+}
+
+// ParseWireFormat converts a name into a [WireFormat]
+func ParseWireFormat(s string) (WireFormat, error) {
+	switch s {
+	case "", "auto":
+		return AutoFormat, nil
+	case "binary", "bin", "pb":
+		return BinaryFormat, nil
+	case "json":
+		return JSONFormat, nil
+	case "text", "txt", "prototext", "textpb":
+		return TextFormat, nil
+	default:
+		return AutoFormat, fmt.Errorf("invalid format %q", s)
+	}
+}
+
+// DetectWireFormat guesses the encoding of serialized content.
+// Content valid as protobuf wire encoding is considered binary,
+// even if printable. Otherwise, UTF-8 text starting with '{' is
+// considered JSON, other printable UTF-8 text is considered text
+// format, and anything else binary.
+func DetectWireFormat(b []byte) WireFormat {
+	switch {
+	case proto.Unmarshal(b, &emptypb.Empty{}) == nil:
+		// including empty content
+		return BinaryFormat
+	case !utf8.Valid(b), bytes.IndexFunc(b, isBinaryRune) >= 0:
+		return BinaryFormat
+	case bytes.HasPrefix(bytes.TrimLeftFunc(b, unicode.IsSpace), []byte("{")):
+		return JSONFormat
+	default:
+		return TextFormat
+	}
+}
+
+func isBinaryRune(r rune) bool {
+	return !unicode.IsPrint(r) && !unicode.IsSpace(r)
+}
+
+// UnmarshalFormat decodes a message in the given [WireFormat],
+// detecting it if [AutoFormat]
+func UnmarshalFormat(b []byte, m proto.Message, format WireFormat) error {
+	return decodeFormat(b, m, format, false, protoregistry.GlobalTypes)
+}
+
+func decodeFormat(b []byte, m proto.Message, format WireFormat,
+	discardUnknown bool, resolver typeResolver) error {
+	if format == AutoFormat {
+		format = DetectWireFormat(b)
+	}
+
+	switch format {
+	case BinaryFormat:
+		return proto.Unmarshal(b, m)
+	case JSONFormat:
+		return protojson.UnmarshalOptions{
+			DiscardUnknown: discardUnknown,
+			Resolver:       resolver,
+		}.Unmarshal(b, m)
+	case TextFormat:
+		return prototext.UnmarshalOptions{
+			DiscardUnknown: discardUnknown,
+			Resolver:       resolver,
+		}.Unmarshal(b, m)
+	default:
+		return fmt.Errorf("invalid format %s", format)
+	}
+}
+
+type typeResolver interface {
+	protoregistry.MessageTypeResolver
+	protoregistry.ExtensionTypeResolver
+}
+
+// unmarshalSelfDescribing decodes a message carrying the descriptors
+// of its own custom options, like a request or a descriptor set. JSON and
+// text are decoded twice, the second time resolving the custom options
+// using the descriptors found the first time.
+func unmarshalSelfDescribing(b []byte, m proto.Message, format WireFormat,
+	files func() []*descriptorpb.FileDescriptorProto) error {
+	if format == AutoFormat {
+		format = DetectWireFormat(b)
+	}
+
+	if format == BinaryFormat {
+		// custom options remain as unknown fields
+		return proto.Unmarshal(b, m)
+	}
+
+	// first pass, without custom options
+	err := decodeFormat(b, m, format, true, protoregistry.GlobalTypes)
+	if err != nil {
+		return err
+	}
+
+	reg, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: files()})
+	if err != nil {
+		return err
+	}
+
+	// second pass
+	proto.Reset(m)
+	return decodeFormat(b, m, format, false, dynamicpb.NewTypes(reg))
+}
+
+// MarshalFormat encodes a message in the given [WireFormat],
+// using [BinaryFormat] if [AutoFormat]. JSON and text are multiline,
+// indented with two spaces, and stable across builds.
+func MarshalFormat(m proto.Message, format WireFormat) ([]byte, error) {
+	switch format {
+	case AutoFormat, BinaryFormat:
+		return proto.Marshal(m)
+	case JSONFormat:
+		return marshalJSON(m)
+	case TextFormat:
+		return marshalText(m)
+	default:
+		return nil, fmt.Errorf("invalid format %s", format)
+	}
+}
+
+// marshalJSON encodes a message using protojson, replacing the
+// whitespace it randomly varies between builds
+func marshalJSON(m proto.Message) ([]byte, error) {
+	b, err := protojson.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	var compact, out bytes.Buffer
+	if err := json.Compact(&compact, b); err != nil {
+		return nil, err
+	}
+	if err := json.Indent(&out, compact.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+
+	_ = out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// marshalText encodes a message using prototext, removing the
+// extra space it randomly adds after field names between builds
+func marshalText(m proto.Message) ([]byte, error) {
+	b, err := prototext.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := bytes.SplitAfter(b, []byte("\n"))
+	for i, line := range lines {
+		lines[i] = stableTextLine(line)
+	}
+	return bytes.Join(lines, nil), nil
+}
+
+// stableTextLine replaces the space after the name of a field
+// of a prototext line, one or two, with a single one
+func stableTextLine(line []byte) []byte {
+	i := bytes.IndexByte(line, ':')
+	if i < 0 || !bytes.HasPrefix(line[i:], []byte(":  ")) {
+		return line
+	}
+
+	out := make([]byte, 0, len(line)-1)
+	out = append(out, line[:i+2]...)
+	return append(out, line[i+3:]...)
+}
+
+func readSelfDescribing(r io.Reader, m proto.Message, format WireFormat,
+	files func() []*descriptorpb.FileDescriptorProto) error {
+	in, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	return unmarshalSelfDescribing(in, m, format, files)
+}
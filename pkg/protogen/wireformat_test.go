@@ -0,0 +1,188 @@
+package protogen
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestParseWireFormat(t *testing.T) {
+	tests := []struct {
+		in   string
+		want WireFormat
+		ok   bool
+	}{
+		{"", AutoFormat, true},
+		{"auto", AutoFormat, true},
+		{"binary", BinaryFormat, true},
+		{"pb", BinaryFormat, true},
+		{"json", JSONFormat, true},
+		{"text", TextFormat, true},
+		{"prototext", TextFormat, true},
+		{"yaml", AutoFormat, false},
+		{"JSON", AutoFormat, false},
+	}
+
+	for _, tc := range tests {
+		got, err := ParseWireFormat(tc.in)
+		switch {
+		case (err == nil) != tc.ok:
+			t.Errorf("ParseWireFormat(%q) error %v", tc.in, err)
+		case got != tc.want:
+			t.Errorf("ParseWireFormat(%q) = %s, expected %s", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestDetectWireFormat(t *testing.T) {
+	req := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{"foo/bar.proto"},
+		Parameter:      proto.String("paths=source_relative"),
+	}
+
+	encode := func(format WireFormat) string {
+		b, err := MarshalFormat(req, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	tests := []struct {
+		name string
+		in   string
+		want WireFormat
+	}{
+		{"empty", "", BinaryFormat},
+		{"whitespace", " \n\t", TextFormat},
+		{"binary", encode(BinaryFormat), BinaryFormat},
+		{"binary printable tag", "\n\x01a", BinaryFormat},
+		{"binary json-like", encodeBinary(t, longNameRequest()), BinaryFormat},
+		{"invalid utf-8", "file_to_generate: \"\xff\"", BinaryFormat},
+		{"json", encode(JSONFormat), JSONFormat},
+		{"json indented", "  {\"fileToGenerate\": [\"a.proto\"]}", JSONFormat},
+		{"text", encode(TextFormat), TextFormat},
+		{"text single line", `file_to_generate: "a.proto" parameter: "x"`, TextFormat},
+		{"text comment", "# comment\nfile_to_generate: \"a.proto\"\n", TextFormat},
+		{"text utf-8", "parameter: \"ñ\"", TextFormat},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := DetectWireFormat([]byte(tc.in)); got != tc.want {
+				t.Errorf("DetectWireFormat(%q) = %s, expected %s", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+// longNameRequest returns a request whose binary encoding starts
+// with "\n{", as the name of the first file is 123 bytes long
+func longNameRequest() *pluginpb.CodeGeneratorRequest {
+	name := strings.Repeat("a", 117) + ".proto"
+	return &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{name},
+	}
+}
+
+func encodeBinary(t *testing.T, m proto.Message) string {
+	b, err := proto.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestUnmarshalCodeGeneratorRequest(t *testing.T) {
+	want := longNameRequest()
+
+	b := encodeBinary(t, want)
+	if !strings.HasPrefix(b, "\n{") {
+		t.Fatalf("encoded request starts with %q", b[:2])
+	}
+
+	got, err := UnmarshalCodeGeneratorRequest(strings.NewReader(b))
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case !proto.Equal(got, want):
+		t.Errorf("got %v, expected %v", got, want)
+	}
+}
+
+func TestMarshalFormat(t *testing.T) {
+	req := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{"foo/bar.proto"},
+		Parameter:      proto.String("paths=source_relative"),
+	}
+
+	tests := []struct {
+		format WireFormat
+		want   string
+	}{
+		{JSONFormat, "{\n  \"fileToGenerate\": [\n    \"foo/bar.proto\"\n  ],\n" +
+			"  \"parameter\": \"paths=source_relative\"\n}\n"},
+		{TextFormat, "file_to_generate: \"foo/bar.proto\"\nparameter: \"paths=source_relative\"\n"},
+	}
+
+	for _, tc := range tests {
+		b, err := MarshalFormat(req, tc.format)
+		switch {
+		case err != nil:
+			t.Errorf("MarshalFormat(%s): %v", tc.format, err)
+		case !bytes.Equal(b, []byte(tc.want)):
+			t.Errorf("MarshalFormat(%s) = %q, expected %q", tc.format, b, tc.want)
+		}
+	}
+}
+
+func TestStableTextLine(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"a: 1\n", "a: 1\n"},
+		{"a:  1\n", "a: 1\n"},
+		{"  a:  {\n", "  a: {\n"},
+		{"  b:  \"x:  y\"\n", "  b: \"x:  y\"\n"},
+		{"}\n", "}\n"},
+	}
+
+	for _, tc := range tests {
+		if got := string(stableTextLine([]byte(tc.in))); got != tc.want {
+			t.Errorf("stableTextLine(%q) = %q, expected %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func checkRoundTrip(t *testing.T, want proto.Message, format WireFormat) {
+	b, err := MarshalFormat(want, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := &pluginpb.CodeGeneratorRequest{}
+	if err := UnmarshalFormat(b, got, AutoFormat); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, want) {
+		t.Errorf("got %v, expected %v", got, want)
+	}
+}
+
+func TestUnmarshalFormat(t *testing.T) {
+	want := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{"foo/bar.proto"},
+		Parameter:      proto.String("paths=source_relative"),
+	}
+
+	for _, format := range []WireFormat{BinaryFormat, JSONFormat, TextFormat} {
+		t.Run(format.String(), func(t *testing.T) {
+			checkRoundTrip(t, want, format)
+		})
+	}
+
+	t.Run("json-like binary", func(t *testing.T) {
+		checkRoundTrip(t, longNameRequest(), BinaryFormat)
+	})
+}